@@ -16,27 +16,48 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/zero-pkg/tpl"
 
+	"github.com/dotzero/hooks/app/broker"
 	"github.com/dotzero/hooks/app/favicon"
+	"github.com/dotzero/hooks/app/notify"
+	"github.com/dotzero/hooks/app/proxy"
 	"github.com/dotzero/hooks/app/storage"
 )
 
 // App is a Hook application
 type App struct {
 	CommonOpts
-	Storage    *storage.BoltDB
+	Storage    storage.Store
+	Broker     *broker.Broker
+	Proxy      *proxy.Client
+	Notifier   *notify.Notifier
 	Templates  *tpl.Templates
 	httpServer *http.Server
 }
 
 const (
-	boltFile = "hooks.db"
+	boltFile      = "hooks.db"
+	sqliteFile    = "hooks.sqlite"
+	replayTimeout = 30 * time.Second
 )
 
 // New prepares application
 func New(commonOpts CommonOpts) (*App, error) {
-	app := &App{}
+	app := &App{
+		Broker: broker.New(),
+	}
 	app.SetCommon(commonOpts)
 
+	if app.MaxTTL < app.BoltTTL {
+		return nil, fmt.Errorf("max ttl %dh is less than ttl %dh", app.MaxTTL, app.BoltTTL)
+	}
+
+	if app.MaxBodySize <= 0 {
+		return nil, fmt.Errorf("max body size %d is not positive", app.MaxBodySize)
+	}
+
+	app.Proxy = proxy.New(replayTimeout, app.MaxBodySize, app.AllowPrivate)
+	app.Notifier = notify.New(app.AppURL, app.SMTP, app.AllowPrivate)
+
 	if err := app.setupDataStore(); err != nil {
 		return nil, err
 	}
@@ -55,6 +76,7 @@ func (a *App) Run(ctx context.Context, address string, port int) error {
 	defer stop()
 
 	a.httpServer = a.makeHTTPServer(address, port, a.routes())
+	a.httpServer.RegisterOnShutdown(a.Broker.Close) // release streaming clients
 
 	go func() {
 		addr := fmt.Sprintf("%s:%d", address, port)
@@ -67,6 +89,7 @@ func (a *App) Run(ctx context.Context, address string, port int) error {
 	}()
 
 	go a.sweep(ctx)
+	go a.Notifier.Run(ctx)
 
 	<-ctx.Done()
 	stop()
@@ -79,13 +102,33 @@ func (a *App) Run(ctx context.Context, address string, port int) error {
 	return a.httpServer.Shutdown(timeoutCtx)
 }
 
-// setupDataStore initializes BoltDB store
+// setupDataStore initializes storage of the configured type
 func (a *App) setupDataStore() error {
+	retention := storage.WithRetention(a.MaxRequests, a.MaxHookSize)
+
+	if a.StoreType == "memory" {
+		a.Storage = storage.NewMemory(retention)
+		return nil
+	}
+
 	if err := makeDirs(a.BoltPath); err != nil {
 		return err
 	}
 
-	store, err := storage.New(filepath.Join(a.BoltPath, boltFile))
+	var (
+		store storage.Store
+		err   error
+	)
+
+	switch a.StoreType {
+	case "", "bolt":
+		store, err = storage.NewBoltDB(filepath.Join(a.BoltPath, boltFile), retention)
+	case "sqlite":
+		store, err = storage.NewSQLite(filepath.Join(a.BoltPath, sqliteFile), retention)
+	default:
+		return fmt.Errorf("unknown store type %q", a.StoreType)
+	}
+
 	if err != nil {
 		return err
 	}
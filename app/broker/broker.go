@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+const (
+	bufferSize = 16
+)
+
+// Broker is an in-process publish/subscribe hub for captured requests
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[string]map[chan *models.Request]struct{}
+	closed bool
+}
+
+// New returns a new broker
+func New() *Broker {
+	return &Broker{
+		subs: make(map[string]map[chan *models.Request]struct{}),
+	}
+}
+
+// Subscribe returns a channel with requests of the hook and a function to cancel the subscription.
+// The channel is closed when the subscription is cancelled or the broker is closed
+func (b *Broker) Subscribe(hook string) (<-chan *models.Request, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan *models.Request, bufferSize)
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subs[hook] == nil {
+		b.subs[hook] = make(map[chan *models.Request]struct{})
+	}
+
+	b.subs[hook][ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[hook][ch]; !ok {
+			return // already closed
+		}
+
+		delete(b.subs[hook], ch)
+		if len(b.subs[hook]) == 0 {
+			delete(b.subs, hook)
+		}
+
+		close(ch)
+	}
+}
+
+// Publish sends request to all subscribers of the hook.
+// It never blocks, slow subscribers miss requests that don't fit into their buffer
+func (b *Broker) Publish(hook string, req *models.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[hook] {
+		select {
+		case ch <- req:
+		default:
+		}
+	}
+}
+
+// Close cancels all subscriptions, subsequent subscriptions are closed immediately
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for hook, subs := range b.subs {
+		for ch := range subs {
+			close(ch)
+		}
+
+		delete(b.subs, hook)
+	}
+
+	b.closed = true
+}
@@ -0,0 +1,69 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+func TestPublish(t *testing.T) {
+	b := New()
+
+	foo, cancelFoo := b.Subscribe("foo")
+	defer cancelFoo()
+
+	bar, cancelBar := b.Subscribe("bar")
+	defer cancelBar()
+
+	req := &models.Request{Name: "req"}
+	b.Publish("foo", req)
+
+	assert.Equal(t, req, <-foo)
+	assert.Len(t, bar, 0)
+}
+
+func TestPublishSlowSubscriber(t *testing.T) {
+	b := New()
+
+	ch, cancel := b.Subscribe("foo")
+	defer cancel()
+
+	for i := 0; i < bufferSize*2; i++ {
+		b.Publish("foo", &models.Request{})
+	}
+
+	assert.Len(t, ch, bufferSize)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	b := New()
+
+	ch, cancel := b.Subscribe("foo")
+	cancel()
+	cancel() // safe to call twice
+
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.Len(t, b.subs, 0)
+
+	b.Publish("foo", &models.Request{})
+}
+
+func TestClose(t *testing.T) {
+	b := New()
+
+	ch, cancel := b.Subscribe("foo")
+
+	b.Close()
+	cancel() // safe after close
+
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	ch, _ = b.Subscribe("foo")
+
+	_, ok = <-ch
+	assert.False(t, ok)
+}
@@ -0,0 +1,420 @@
+package curl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidCommand is returned when the command can not be parsed
+var ErrInvalidCommand = errors.New("invalid curl command")
+
+// command is the request described by the curl flags
+type command struct {
+	method  string
+	url     string
+	headers http.Header
+	data    []string
+	form    [][2]string
+	user    string
+	get     bool
+	head    bool
+}
+
+// Parse returns the request sent by the curl command.
+// It supports the common flags: -X, -H, -d, --data-raw, --data-binary, --data-urlencode, -F, -u, -G, -I,
+// -A, -e and -b. Other flags are ignored, reading data or files from disk is not supported
+func Parse(cmd string) (*http.Request, error) {
+	args, err := split(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, fmt.Errorf("%w: must start with curl", ErrInvalidCommand)
+	}
+
+	c, err := parseArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return c.request()
+}
+
+// parseArgs collects the values of the supported flags
+func parseArgs(args []string) (*command, error) {
+	c := &command{headers: http.Header{}}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if err := c.set("--url", arg); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		name, value, attached := c.splitFlag(arg)
+
+		if !takesValue(name) {
+			c.toggle(name)
+
+			continue
+		}
+
+		if !attached {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%w: %s requires a value", ErrInvalidCommand, name)
+			}
+
+			i++
+			value = args[i]
+		}
+
+		if err := c.set(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.url == "" {
+		return nil, fmt.Errorf("%w: url is missing", ErrInvalidCommand)
+	}
+
+	return c, nil
+}
+
+// splitFlag splits the flag from its value attached like -XPOST or --request=POST.
+// Boolean short flags combined before it like -sSX POST or -Gd a=b are toggled on the way
+func (c *command) splitFlag(arg string) (name string, value string, attached bool) {
+	if strings.HasPrefix(arg, "--") {
+		if i := strings.Index(arg, "="); i > 0 {
+			return arg[:i], arg[i+1:], true
+		}
+
+		return arg, "", false
+	}
+
+	for i := 1; i < len(arg); i++ {
+		flag := "-" + arg[i:i+1]
+
+		if takesValue(flag) {
+			return flag, arg[i+1:], i+1 < len(arg)
+		}
+
+		c.toggle(flag)
+	}
+
+	return arg, "", false
+}
+
+// toggle sets the supported boolean flag, others are ignored
+func (c *command) toggle(name string) {
+	switch name {
+	case "-G", "--get":
+		c.get = true
+	case "-I", "--head":
+		c.head = true
+	}
+}
+
+// takesValue reports whether the flag is followed by a value
+func takesValue(name string) bool {
+	switch name {
+	case "-X", "--request", "-H", "--header", "-d", "--data", "--data-ascii", "--data-raw", "--data-binary",
+		"--data-urlencode", "-F", "--form", "--form-string", "-u", "--user", "-A", "--user-agent",
+		"-e", "--referer", "-b", "--cookie", "--url",
+		"-o", "--output", "-m", "--max-time", "--connect-timeout", "-x", "--proxy", "-w", "--write-out",
+		"--retry", "--cacert", "--cert", "--key", "-E", "--resolve", "-c", "--cookie-jar",
+		"--max-redirs", "--limit-rate", "-K", "--config", "-T", "--upload-file", "--interface", "-r", "--range",
+		"-y", "--speed-time", "-Y", "--speed-limit", "--max-filesize", "--retry-delay", "--retry-max-time",
+		"-U", "--proxy-user", "--noproxy", "--connect-to", "--local-port", "--keepalive-time",
+		"--expect100-timeout", "--dns-servers", "-D", "--dump-header", "--trace", "--trace-ascii", "--stderr",
+		"-z", "--time-cond", "-C", "--continue-at", "--capath", "--cert-type", "--key-type", "--pass",
+		"--ciphers", "--tls-max", "--proto", "--proto-redir", "--unix-socket", "--output-dir":
+		return true
+	}
+
+	return false
+}
+
+func (c *command) set(name string, value string) error {
+	switch name {
+	case "-X", "--request":
+		c.method = strings.ToUpper(value)
+	case "-H", "--header":
+		i := strings.Index(value, ":")
+		if i <= 0 {
+			return fmt.Errorf("%w: malformed header %q", ErrInvalidCommand, value)
+		}
+
+		// "Name:" removes the header in curl, there is nothing to remove here
+		if v := strings.TrimSpace(value[i+1:]); v != "" {
+			c.headers.Add(strings.TrimSpace(value[:i]), v)
+		}
+	case "-d", "--data", "--data-ascii", "--data-binary":
+		if strings.HasPrefix(value, "@") {
+			return fmt.Errorf("%w: reading data from %s is not supported", ErrInvalidCommand, value[1:])
+		}
+
+		c.data = append(c.data, value)
+	case "--data-raw":
+		c.data = append(c.data, value)
+	case "--data-urlencode":
+		c.data = append(c.data, urlEncode(value))
+	case "-F", "--form", "--form-string":
+		i := strings.Index(value, "=")
+		if i <= 0 {
+			return fmt.Errorf("%w: malformed form field %q", ErrInvalidCommand, value)
+		}
+
+		if name != "--form-string" && (strings.HasPrefix(value[i+1:], "@") || strings.HasPrefix(value[i+1:], "<")) {
+			return fmt.Errorf("%w: uploading file %s is not supported", ErrInvalidCommand, value[i+2:])
+		}
+
+		c.form = append(c.form, [2]string{value[:i], value[i+1:]})
+	case "-u", "--user":
+		c.user = value
+	case "-A", "--user-agent":
+		c.headers.Set("User-Agent", value)
+	case "-e", "--referer":
+		c.headers.Set("Referer", value)
+	case "-b", "--cookie":
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("%w: reading cookies from %s is not supported", ErrInvalidCommand, value)
+		}
+
+		c.headers.Add("Cookie", value)
+	case "-T", "--upload-file", "-K", "--config":
+		return fmt.Errorf("%w: reading %s from %s is not supported", ErrInvalidCommand, name, value)
+	case "--url":
+		if c.url != "" {
+			return fmt.Errorf("%w: unexpected argument %s, only one url is supported", ErrInvalidCommand, value)
+		}
+
+		c.url = value
+	}
+
+	return nil
+}
+
+// urlEncode encodes the value of --data-urlencode: "content", "=content" or "name=content"
+func urlEncode(value string) string {
+	i := strings.Index(value, "=")
+
+	switch {
+	case i < 0:
+		return url.QueryEscape(value)
+	case i == 0:
+		return url.QueryEscape(value[1:])
+	default:
+		return value[:i] + "=" + url.QueryEscape(value[i+1:])
+	}
+}
+
+func (c *command) request() (*http.Request, error) {
+	rawURL := c.url
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCommand, err)
+	}
+
+	var body []byte
+
+	method := http.MethodGet
+
+	switch {
+	case len(c.form) > 0:
+		method = http.MethodPost
+
+		body, err = c.multipart()
+		if err != nil {
+			return nil, err
+		}
+	case len(c.data) > 0 && c.get:
+		data := strings.Join(c.data, "&")
+		if u.RawQuery != "" {
+			data = u.RawQuery + "&" + data
+		}
+
+		u.RawQuery = data
+	case len(c.data) > 0:
+		method = http.MethodPost
+		body = []byte(strings.Join(c.data, "&"))
+
+		if c.headers.Get("Content-Type") == "" {
+			c.headers.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+
+	if c.head {
+		method = http.MethodHead
+	}
+
+	if c.method != "" {
+		method = c.method
+	}
+
+	r, err := http.NewRequest(method, u.String(), ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCommand, err)
+	}
+
+	r.Header = c.headers
+	r.ContentLength = int64(len(body))
+
+	if c.user != "" {
+		user, password := c.user, ""
+		if i := strings.Index(user, ":"); i >= 0 {
+			user, password = user[:i], user[i+1:]
+		}
+
+		r.SetBasicAuth(user, password)
+	}
+
+	return r, nil
+}
+
+// multipart returns the multipart body of -F fields and sets its content type
+func (c *command) multipart() ([]byte, error) {
+	var buf bytes.Buffer
+
+	mw := multipart.NewWriter(&buf)
+
+	for _, field := range c.form {
+		// curl allows ";type=..." after the value, it is dropped here
+		value := field[1]
+		if i := strings.Index(value, ";type="); i >= 0 {
+			value = value[:i]
+		}
+
+		if err := mw.WriteField(field[0], value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	c.headers.Set("Content-Type", mw.FormDataContentType())
+
+	return buf.Bytes(), nil
+}
+
+// split splits the command into arguments the way POSIX shells do,
+// handling quotes, backslash escapes, line continuations and ANSI-C $'...' strings
+func split(cmd string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		started bool
+	)
+
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+
+		switch {
+		case c == '\\' && i+1 < len(cmd):
+			i++
+			if cmd[i] != '\n' {
+				arg.WriteByte(cmd[i])
+				started = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(cmd[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidCommand)
+			}
+
+			arg.WriteString(cmd[i+1 : i+1+end])
+			i += end + 1
+			started = true
+		case c == '$' && i+1 < len(cmd) && cmd[i+1] == '\'':
+			n, err := ansiString(cmd[i+2:], &arg)
+			if err != nil {
+				return nil, err
+			}
+
+			i += n + 2
+			started = true
+		case c == '"':
+			n, err := doubleQuoted(cmd[i+1:], &arg)
+			if err != nil {
+				return nil, err
+			}
+
+			i += n + 1
+			started = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if started {
+				args = append(args, arg.String())
+				arg.Reset()
+				started = false
+			}
+		default:
+			arg.WriteByte(c)
+			started = true
+		}
+	}
+
+	if started {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+// doubleQuoted writes the content of "..." string and returns the index of the closing quote
+func doubleQuoted(s string, arg *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return i, nil
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) >= 0:
+			i++
+			if s[i] != '\n' {
+				arg.WriteByte(s[i])
+			}
+		default:
+			arg.WriteByte(c)
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unterminated quote", ErrInvalidCommand)
+}
+
+// ansiString writes the content of $'...' string and returns the index of the closing quote
+func ansiString(s string, arg *strings.Builder) (int, error) {
+	escapes := map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', '\\': '\\', '\'': '\'', '"': '"'}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'':
+			return i, nil
+		case c == '\\' && i+1 < len(s):
+			i++
+			if e, ok := escapes[s[i]]; ok {
+				arg.WriteByte(e)
+			} else {
+				arg.WriteByte('\\')
+				arg.WriteByte(s[i])
+			}
+		default:
+			arg.WriteByte(c)
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unterminated quote", ErrInvalidCommand)
+}
@@ -0,0 +1,118 @@
+package curl
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	r, err := Parse(`curl -X POST 'http://localhost:8080/foo/events?x=1' \
+  -H 'Content-Type: application/json' \
+  -H "X-Tag: a" -H 'X-Tag: b' \
+  --data-raw '{"msg":"it'\''s \"ok\""}'`)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "http://localhost:8080/foo/events?x=1", r.URL.String())
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"a", "b"}, r.Header["X-Tag"])
+
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"msg":"it's \"ok\""}`, string(body))
+	assert.Equal(t, int64(len(body)), r.ContentLength)
+}
+
+func TestParseData(t *testing.T) {
+	r, err := Parse(`curl localhost:8080/foo -d a=1 --data b=2 --data-urlencode "c=x y" -u user:secret`)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "http://localhost:8080/foo", r.URL.String())
+	assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+	require.NoError(t, r.ParseForm())
+	assert.Equal(t, "1", r.PostForm.Get("a"))
+	assert.Equal(t, "2", r.PostForm.Get("b"))
+	assert.Equal(t, "x y", r.PostForm.Get("c"))
+
+	user, password, ok := r.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "secret", password)
+
+	r, err = Parse(`curl -G 'http://localhost/foo?x=1' -d y=2 -XPUT`)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPut, r.Method)
+	assert.Equal(t, "x=1&y=2", r.URL.RawQuery)
+	assert.Equal(t, int64(0), r.ContentLength)
+
+	r, err = Parse(`curl -sSI --url=http://localhost/foo -A agent/1.0 -b 'a=1' -e http://example.com`)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodHead, r.Method)
+	assert.Equal(t, "agent/1.0", r.Header.Get("User-Agent"))
+	assert.Equal(t, "a=1", r.Header.Get("Cookie"))
+	assert.Equal(t, "http://example.com", r.Header.Get("Referer"))
+}
+
+func TestParseValueFlags(t *testing.T) {
+	r, err := Parse(`curl -sL --max-redirs 3 --limit-rate 100K --interface eth0 -r 0-99 http://localhost/foo`)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodGet, r.Method)
+	assert.Equal(t, "http://localhost/foo", r.URL.String())
+}
+
+func TestParseCombinedFlags(t *testing.T) {
+	r, err := Parse(`curl -Gd 'a=b' http://localhost/foo`)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodGet, r.Method)
+	assert.Equal(t, "http://localhost/foo?a=b", r.URL.String())
+
+	r, err = Parse(`curl -sIH 'X-Tag: a' http://localhost/foo`)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodHead, r.Method)
+	assert.Equal(t, "a", r.Header.Get("X-Tag"))
+}
+
+func TestParseForm(t *testing.T) {
+	r, err := Parse(`curl http://localhost/foo -F name=John -F 'note=hello world;type=text/plain'`)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, r.Method)
+	require.NoError(t, r.ParseMultipartForm(1024))
+	assert.Equal(t, "John", r.PostFormValue("name"))
+	assert.Equal(t, "hello world", r.PostFormValue("note"))
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, cmd := range []string{
+		"",
+		"wget http://localhost",
+		"curl",
+		"curl -X",
+		"curl 'http://localhost",
+		"curl http://localhost -d @body.json",
+		"curl http://localhost -F file=@photo.jpg",
+		"curl http://localhost -H malformed",
+		"curl http://localhost http://example.com",
+		"curl http://localhost --unknown value",
+		"curl http://localhost -T body.json",
+		"curl -K curlrc http://localhost",
+	} {
+		_, err := Parse(cmd)
+		assert.ErrorIs(t, err, ErrInvalidCommand, cmd)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	args, err := split("curl  'a b'\"c\\\"d\" e\\ f \\\n $'g\\nh' \"$x\"")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"curl", "a bc\"d", "e f", "g\nh", "$x"}, args)
+}
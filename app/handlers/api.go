@@ -1,72 +1,738 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 
+	"github.com/dotzero/hooks/app/curl"
+	"github.com/dotzero/hooks/app/har"
 	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/network"
+	"github.com/dotzero/hooks/app/proxy"
+	"github.com/dotzero/hooks/app/signature"
+	"github.com/dotzero/hooks/app/snippet"
+	"github.com/dotzero/hooks/app/storage"
 )
 
-// APICreate handle the creation of new hooks
-func APICreate(s store) http.HandlerFunc {
+// maxImportSize is the max size of an imported HAR document or curl command
+const maxImportSize = 32 << 20
+
+// APICreate handle the creation of new hooks.
+// The hook lives for the requested ttl bounded by maxTTL or for the default ttl
+func APICreate(s store, ttl time.Duration, maxTTL time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		user, err := currentUser(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		hookTTL, err := parseDuration(r.Form, "ttl", ttl)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if hookTTL < minTTL || hookTTL > maxTTL {
+			renderError(w, r, fmt.Errorf("%w: ttl must be between %s and %s", errBadRequest, minTTL, maxTTL))
+			return
+		}
+
+		name := r.Form.Get("name")
+		if name != "" {
+			if err := models.ValidateName(name); err != nil {
+				renderError(w, r, fmt.Errorf("%w: %s", errBadRequest, err))
+				return
+			}
+		}
+
+		private := parseBool(r.Form, "private")
+		hook := models.NewHook(private)
+		hook.Expires = hook.Created.Add(hookTTL)
+
+		if name != "" {
+			hook.Name = name
+		}
+
+		// hooks of signed in users are kept until deleted by the owner
+		if user != "" {
+			hook.Owner = user
+			hook.Expires = time.Time{}
+			hookTTL = sessionTTL
+		}
+
+		if err := s.PutHook(hook); err != nil {
+			if errors.Is(err, storage.ErrHookExists) {
+				err = fmt.Errorf("%w: %s", errConflict, err)
+			}
+
+			renderError(w, r, err)
+
+			return
+		}
+
+		// the creator keeps the secret to access private hooks
+		setAccessCookie(w, hook, hookTTL)
+
+		render.JSON(w, r, hook)
+	}
+}
+
+// APIHook handle requests to hooks.
+// Requests to hooks with an upstream are forwarded and the upstream response is relayed back
+func APIHook(s store, p publisher, f replayer, n notifier, maxBodySize int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := s.Hook(chi.URLParam(r, urlParam))
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if hook == nil {
+			renderError(w, r, errNotFound)
+			return
+		}
+
+		// the request has been forwarded by hooks already, e.g. to the hook itself
+		if hook.Forward != nil && proxy.Looped(r.Header) {
+			renderError(w, r, errLoopDetected)
+			return
+		}
+
+		stripAppCookies(r.Header)
+
+		var verifier *signature.Verifier
+		if hook.Signature != nil {
+			// the verifier hashes the whole body as it is read, including the part past maxBodySize
+			verifier = signature.New(hook.Signature, r.Header, time.Now())
+			r.Body = readCloser{io.TeeReader(r.Body, verifier), r.Body}
+		}
+
+		req := models.NewRequest(r, maxBodySize)
+
+		if verifier != nil {
+			req.Signature = verifier.Check()
+		}
+
+		if hook.Forward != nil {
+			req.Upstream = forward(r.Context(), f, hook, req)
+		} else if hook.Response != nil {
+			req.Mock = hook.Response.Reply(req)
+		}
+
+		if err := s.PutRequest(hook.Name, req); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		p.Publish(hook.Name, req)
+		n.Notify(hook, req)
+
+		if req.Upstream != nil {
+			writeUpstream(w, r, req)
+			return
+		}
+
+		if req.Mock != nil {
+			if err := writeResponse(w, r, req.Mock); err != nil {
+				renderError(w, r, err)
+			}
+
+			return
+		}
+
+		render.JSON(w, r, req)
+	}
+}
+
+// APIGetHook handle hook details, the secret is shown to clients who could change settings
+func APIGetHook(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := accessibleHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		configurable, err := canConfigure(s, r, hook)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if !configurable {
+			hook.Secret = ""
+		}
+
+		hook.Signature = hook.Signature.Masked()
+
+		render.JSON(w, r, hook)
+	}
+}
+
+// APIRequests handle listing of hook requests, newest first
+func APIRequests(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := accessibleHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		q, err := parseRequestsQuery(r.URL.Query())
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		requests, next, err := requestsPage(s, hook.Name, q)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.JSON(w, r, map[string]interface{}{
+			"requests": requests,
+			"next":     next,
+		})
+	}
+}
+
+// APIExportHAR handle export of hook requests as HTTP Archive, oldest first.
+// Requests are selected by names or by the listing filters
+func APIExportHAR(s store, baseURL string, version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := accessibleHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		requests, err := exportedRequests(s, hook.Name, r.URL.Query())
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", hook.Name+".har"))
+		render.JSON(w, r, har.New(baseURL, version, requests))
+	}
+}
+
+// APIImport handle import of requests from HAR document or curl command into the hook.
+// The document is uploaded as the file field or posted as the data field, imported requests keep their time
+func APIImport(s store, maxBodySize int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		data, err := importData(w, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		imported, err := importRequests(hook.Name, data, maxBodySize)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		names := make([]string, 0, len(imported))
+
+		for _, req := range imported {
+			if err := s.PutRequest(hook.Name, req); err != nil {
+				renderError(w, r, err)
+				return
+			}
+
+			names = append(names, req.Name)
+		}
+
+		render.JSON(w, r, map[string]interface{}{
+			"imported": len(names),
+			"requests": names,
+		})
+	}
+}
+
+// APIRequest handle single hook request
+func APIRequest(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, req, err := accessibleRequest(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.JSON(w, r, req)
+	}
+}
+
+// APINextRequest handle waiting for the next request to the hook.
+// It responds with the oldest matched request stored after the given one or waits for a new one
+func APINextRequest(s store, sub subscriber) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := accessibleHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		q, err := parseRequestsQuery(r.URL.Query())
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		timeout, err := parseDuration(r.URL.Query(), "timeout", defaultWait)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if timeout <= 0 || timeout > maxWait {
+			renderError(w, r, fmt.Errorf("%w: timeout must be between 0s and %s", errBadRequest, maxWait))
+			return
+		}
+
+		// subscribe before looking into storage to not miss concurrent requests
+		ch, cancel := sub.Subscribe(hook.Name)
+		defer cancel()
+
+		if after := r.URL.Query().Get("after"); after != "" {
+			req, err := nextRequest(s, hook.Name, after, q)
+			if err != nil {
+				renderError(w, r, err)
+				return
+			}
+
+			if req != nil {
+				render.JSON(w, r, req)
+				return
+			}
+		}
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		for {
+			select {
+			case req, ok := <-ch:
+				if !ok {
+					render.NoContent(w, r)
+					return
+				}
+
+				if q.Match(req) {
+					render.JSON(w, r, req)
+					return
+				}
+			case <-timer.C:
+				render.NoContent(w, r)
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+// APIRequestBody handle download of the raw request body
+func APIRequestBody(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, req, err := accessibleRequest(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		contentType := req.Headers.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		// never let browsers render captured content on the app origin
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", req.Name))
+		w.Header().Set("Content-Length", strconv.Itoa(len(req.Body)))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		if req.BodyTruncated {
+			w.Header().Set(headerOriginalSize, strconv.FormatInt(req.BodySize, 10))
+		}
+
+		_, _ = w.Write(req.Body)
+	}
+}
+
+// APIRequestSnippets handle code snippets reproducing the hook request,
+// a single snippet is returned as plain text by its language name
+func APIRequestSnippets(s store, baseURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, req, err := accessibleRequest(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		lang := r.URL.Query().Get("lang")
+		if lang == "" {
+			render.JSON(w, r, map[string]interface{}{
+				"snippets": snippet.All(baseURL, req),
+			})
+
+			return
+		}
+
+		sn, ok := snippet.Get(lang, baseURL, req)
+		if !ok {
+			renderError(w, r, fmt.Errorf("%w: lang must be one of %s", errBadRequest, strings.Join(snippet.Names(), ", ")))
+			return
+		}
+
+		render.PlainText(w, r, sn.Code)
+	}
+}
+
+// APIRequestFile handle download of the request file attachment
+func APIRequestFile(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, req, err := accessibleRequest(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		index, err := strconv.Atoi(chi.URLParam(r, fileParam))
+		if err != nil {
+			renderError(w, r, errFileNotFound)
+			return
+		}
+
+		file, data, err := req.Attachment(index)
+		if errors.Is(err, models.ErrFileNotFound) {
+			renderError(w, r, errFileNotFound)
+			return
+		}
+
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		_, _ = w.Write(data)
+	}
+}
+
+// APIReplayRequest handle replay of the captured request to the target url
+func APIReplayRequest(s store, rp replayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		req, err := hookRequest(s, r, hook)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		target := r.Form.Get("url")
+		if target == "" {
+			renderError(w, r, fmt.Errorf("%w: url is required", errBadRequest))
+			return
+		}
+
+		reply, err := rp.Replay(r.Context(), target, hook.Name, req)
+		if errors.Is(err, proxy.ErrInvalidURL) {
+			renderError(w, r, fmt.Errorf("%w: %v", errBadRequest, err))
+			return
+		}
+
+		if errors.Is(err, network.ErrForbiddenAddress) {
+			renderError(w, r, fmt.Errorf("%w: %v", errForbidden, err))
+			return
+		}
+
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		req.AddReplay(reply)
+
+		if err := s.UpdateRequest(hook.Name, req); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.JSON(w, r, reply)
+	}
+}
+
+// APIUpdateNotifications handle setup of notifications about requests to the hook
+func APIUpdateNotifications(s store, n notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		cfg, err := parseNotifications(r.PostForm)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		owned, err := ownsHook(s, r, hook)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		// the form of other clients has no email field, recipients set by the owner are kept
+		if _, ok := r.PostForm["email"]; !ok && !owned && hook.Notifications != nil {
+			cfg.Email = hook.Notifications.Email
+		} else if len(cfg.Email) > 0 {
+			if !n.EmailEnabled() {
+				renderError(w, r, fmt.Errorf("%w: email notifications require SMTP server", errBadRequest))
+				return
+			}
+
+			// emails are sent on behalf of the server, so recipients are chosen by signed in owners only
+			if !owned {
+				renderError(w, r, fmt.Errorf("%w: email notifications are available to the hook owner", errForbidden))
+				return
+			}
+		}
+
+		hook.Notifications = cfg
+
+		if err := s.UpdateHook(hook); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.JSON(w, r, hook.Notifications)
+	}
+}
+
+// APIDeleteNotifications handle reset of hook notifications
+func APIDeleteNotifications(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		hook.Notifications = nil
+
+		if err := s.UpdateHook(hook); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.NoContent(w, r)
+	}
+}
+
+// APIUpdateResponse handle configuration of hook mock response
+func APIUpdateResponse(s store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
 		if err := r.ParseForm(); err != nil {
 			renderError(w, r, err)
 			return
 		}
 
-		private := parseBool(r.Form, "private")
-		hook := models.NewHook(private)
+		resp, err := parseResponse(r.PostForm)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
 
-		if err := s.PutHook(hook); err != nil {
+		hook.Response = resp
+
+		if err := s.UpdateHook(hook); err != nil {
 			renderError(w, r, err)
 			return
 		}
 
-		if private {
-			cookie := &http.Cookie{
-				Name:     cookiePrefix + hook.Name,
-				Value:    hook.Secret,
-				Path:     "/",
-				MaxAge:   86400, // 24 hours
-				HttpOnly: false,
-			}
-			http.SetCookie(w, cookie)
+		render.JSON(w, r, hook.Response)
+	}
+}
+
+// APIDeleteResponse handle reset of hook mock response
+func APIDeleteResponse(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
 		}
 
-		render.JSON(w, r, hook)
+		hook.Response = nil
+
+		if err := s.UpdateHook(hook); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.NoContent(w, r)
 	}
 }
 
-// APIHook handle requests to hooks
-func APIHook(s store) http.HandlerFunc {
+// APIUpdateForward handle configuration of hook upstream
+func APIUpdateForward(s store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
 		if err := r.ParseForm(); err != nil {
 			renderError(w, r, err)
 			return
 		}
 
-		hook, err := s.Hook(chi.URLParam(r, urlParam))
+		fwd, err := parseForward(r.PostForm)
 		if err != nil {
 			renderError(w, r, err)
 			return
 		}
 
-		if hook == nil {
-			renderError(w, r, errNotFound)
+		hook.Forward = fwd
+
+		if err := s.UpdateHook(hook); err != nil {
+			renderError(w, r, err)
 			return
 		}
 
-		req := models.NewRequest(r)
+		render.JSON(w, r, hook.Forward)
+	}
+}
 
-		if err := s.PutRequest(hook.Name, req); err != nil {
+// APIDeleteForward handle reset of hook upstream
+func APIDeleteForward(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
 			renderError(w, r, err)
 			return
 		}
 
-		render.JSON(w, r, req)
+		hook.Forward = nil
+
+		if err := s.UpdateHook(hook); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.NoContent(w, r)
+	}
+}
+
+// APIUpdateSignature handle configuration of hook signature verification
+func APIUpdateSignature(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		// the query could carry the hook secret, it is not the signing one
+		sig, err := parseSignature(r.PostForm, hook.Signature)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		hook.Signature = sig
+
+		if err := s.UpdateHook(hook); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.JSON(w, r, hook.Signature.Masked())
+	}
+}
+
+// APIDeleteSignature handle reset of hook signature verification
+func APIDeleteSignature(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := configurableHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		hook.Signature = nil
+
+		if err := s.UpdateHook(hook); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.NoContent(w, r)
 	}
 }
 
@@ -95,3 +761,375 @@ func APIStats(s store, ttl int) http.HandlerFunc {
 		})
 	}
 }
+
+// accessibleHook returns hook from url if it exists and could be accessed by client
+func accessibleHook(s store, r *http.Request) (*models.Hook, error) {
+	hook, err := s.Hook(chi.URLParam(r, urlParam))
+	if err != nil {
+		return nil, err
+	}
+
+	if hook == nil {
+		return nil, errNotFound
+	}
+
+	ok, err := canAccess(s, r, hook)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, errNotFound
+	}
+
+	return hook, nil
+}
+
+// accessibleRequest returns hook and its request from url
+func accessibleRequest(s store, r *http.Request) (*models.Hook, *models.Request, error) {
+	hook, err := accessibleHook(s, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := hookRequest(s, r, hook)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hook, req, nil
+}
+
+// hookRequest returns request of the hook from url
+func hookRequest(s store, r *http.Request, hook *models.Hook) (*models.Request, error) {
+	req, err := s.Request(hook.Name, chi.URLParam(r, requestParam))
+	if err != nil {
+		return nil, err
+	}
+
+	if req == nil {
+		return nil, errRequestNotFound
+	}
+
+	return req, nil
+}
+
+// exportedRequests returns requests selected by "request" names or matched by the filters, oldest first
+func exportedRequests(s store, hook string, form url.Values) ([]*models.Request, error) {
+	if names := form["request"]; len(names) > 0 {
+		requests := make([]*models.Request, 0, len(names))
+
+		for _, name := range names {
+			req, err := s.Request(hook, name)
+			if err != nil {
+				return nil, err
+			}
+
+			if req == nil {
+				return nil, fmt.Errorf("%w: %s", errRequestNotFound, name)
+			}
+
+			requests = append(requests, req)
+		}
+
+		sort.Slice(requests, func(i, j int) bool {
+			return requests[i].Created.Before(requests[j].Created)
+		})
+
+		return requests, nil
+	}
+
+	if form.Get("limit") == "" {
+		form.Set("limit", strconv.Itoa(maxLimit))
+	}
+
+	q, err := parseRequestsQuery(form)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, _, err := requestsPage(s, hook, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(requests)-1; i < j; i, j = i+1, j-1 {
+		requests[i], requests[j] = requests[j], requests[i]
+	}
+
+	return requests, nil
+}
+
+// importData returns the uploaded file or the posted data field
+func importData(w http.ResponseWriter, r *http.Request) (string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if err := r.ParseMultipartForm(maxImportSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return "", fmt.Errorf("%w: %s", errBadRequest, err)
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		if data := strings.TrimSpace(r.FormValue("data")); data != "" {
+			return data, nil
+		}
+
+		return "", fmt.Errorf("%w: file or data is required", errBadRequest)
+	}
+
+	defer file.Close() // nolint
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// importRequests parses HAR document or curl command into the hook requests,
+// the path of each request is moved under the hook one
+func importRequests(hook string, data string, maxBodySize int64) ([]*models.Request, error) {
+	if strings.HasPrefix(data, "curl") {
+		r, err := curl.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errBadRequest, err)
+		}
+
+		return []*models.Request{importRequest(hook, r, time.Now(), maxBodySize)}, nil
+	}
+
+	if !strings.HasPrefix(data, "{") {
+		return nil, fmt.Errorf("%w: data must be HAR document or curl command", errBadRequest)
+	}
+
+	doc, err := har.Decode(strings.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errBadRequest, err)
+	}
+
+	requests := make([]*models.Request, 0, len(doc.Log.Entries))
+
+	for i := range doc.Log.Entries {
+		entry := &doc.Log.Entries[i]
+
+		r, err := entry.HTTPRequest()
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d: %s", errBadRequest, i, err)
+		}
+
+		created := entry.StartedDateTime
+		if created.IsZero() {
+			created = time.Now()
+		}
+
+		requests = append(requests, importRequest(hook, r, created, maxBodySize))
+	}
+
+	return requests, nil
+}
+
+// importRequest returns the request model as if it was sent to the hook at the given time.
+// The path is kept if it already points to the hook, e.g. requests exported from it
+func importRequest(hook string, r *http.Request, created time.Time, maxBodySize int64) *models.Request {
+	prefix := "/" + hook
+	if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+		r.URL.Path = prefix + strings.TrimSuffix(r.URL.Path, "/")
+		r.URL.RawPath = ""
+	}
+
+	return models.NewRequestAt(r, maxBodySize, created)
+}
+
+// nextRequest returns the oldest matched request stored after the given one
+func nextRequest(s store, hook string, after string, q *requestsQuery) (*models.Request, error) {
+	last, err := s.Request(hook, after)
+	if err != nil {
+		return nil, err
+	}
+
+	if last == nil {
+		return nil, errRequestNotFound
+	}
+
+	return s.NextRequest(hook, last.Name, q.Match)
+}
+
+func parseResponse(form url.Values) (*models.Response, error) {
+	status, err := parseInt(form, "status", http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	delay, err := parseInt(form, "delay", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := parseHeaderLines(form.Get("headers"))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.Response{
+		Status:      status,
+		ContentType: form.Get("content_type"),
+		Headers:     headers,
+		Body:        form.Get("body"),
+		Delay:       delay,
+	}
+
+	if err := resp.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+
+	return resp, nil
+}
+
+func parseForward(form url.Values) (*models.Forward, error) {
+	timeout, err := parseInt(form, "timeout", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fwd := &models.Forward{
+		URL:     form.Get("url"),
+		Timeout: timeout,
+	}
+
+	if err := fwd.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+
+	return fwd, nil
+}
+
+// parseSignature parses the signature configuration, an empty secret keeps the current one
+func parseSignature(form url.Values, current *models.Signature) (*models.Signature, error) {
+	tolerance, err := parseInt(form, "tolerance", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := &models.Signature{
+		Provider:  form.Get("provider"),
+		Secret:    form.Get("secret"),
+		Header:    strings.TrimSpace(form.Get("header")),
+		Tolerance: tolerance,
+	}
+
+	if sig.Secret == "" && current != nil {
+		sig.Secret = current.Secret
+	}
+
+	if err := sig.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+
+	return sig, nil
+}
+
+func parseNotifications(form url.Values) (*models.Notifications, error) {
+	rate, err := parseInt(form, "rate", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &models.Notifications{
+		Webhook: strings.TrimSpace(form.Get("webhook")),
+		Slack:   strings.TrimSpace(form.Get("slack")),
+		Rate:    rate,
+	}
+
+	for _, addr := range strings.Split(form.Get("email"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.Email = append(cfg.Email, addr)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+
+	return cfg, nil
+}
+
+// forward sends request to the hook upstream, failures are recorded in the reply
+func forward(ctx context.Context, f replayer, hook *models.Hook, req *models.Request) *models.Reply {
+	if req.BodyTruncated {
+		return models.NewFailedReply(hook.Forward.URL, errBodyTooLarge, 0)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hook.Forward.TimeoutDuration())
+	defer cancel()
+
+	reply, err := f.Replay(ctx, hook.Forward.URL, hook.Name, req)
+	if err != nil {
+		return models.NewFailedReply(hook.Forward.URL, err, 0)
+	}
+
+	return reply
+}
+
+// writeUpstream relays the upstream response of forwarded request
+func writeUpstream(w http.ResponseWriter, r *http.Request, req *models.Request) {
+	reply := req.Upstream
+
+	switch {
+	case req.BodyTruncated:
+		render.Status(r, http.StatusRequestEntityTooLarge)
+		render.JSON(w, r, map[string]string{"error": reply.Error})
+		return
+	case reply.Error != "":
+		render.Status(r, http.StatusBadGateway)
+		render.JSON(w, r, map[string]string{"error": reply.Error})
+		return
+	case reply.BodyTruncated:
+		render.Status(r, http.StatusBadGateway)
+		render.JSON(w, r, map[string]string{"error": errUpstreamTooLarge.Error()})
+		return
+	}
+
+	for name, values := range reply.Headers {
+		w.Header()[name] = values
+	}
+
+	proxy.RemoveHopHeaders(w.Header())
+	sandbox(w.Header())
+
+	w.WriteHeader(reply.Status)
+	_, _ = w.Write(reply.Body)
+}
+
+// writeResponse waits for response delay and writes mock response rendered at capture
+func writeResponse(w http.ResponseWriter, r *http.Request, reply *models.Reply) error {
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+
+	if reply.Latency > 0 {
+		select {
+		case <-time.After(reply.Latency):
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+
+	for name, values := range reply.Headers {
+		w.Header()[name] = values
+	}
+
+	sandbox(w.Header())
+
+	w.WriteHeader(reply.Status)
+	_, _ = w.Write(reply.Body)
+
+	return nil
+}
+
+// sandbox keeps content of hook responses, chosen by the hook owner or the upstream,
+// from running scripts on the origin of the application
+func sandbox(h http.Header) {
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Content-Security-Policy", "sandbox")
+}
@@ -1,16 +1,29 @@
 package handlers
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/dotzero/hooks/app/har"
 	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/network"
+	"github.com/dotzero/hooks/app/proxy"
+	"github.com/dotzero/hooks/app/storage"
 )
 
 func TestAPICreate(t *testing.T) {
@@ -22,7 +35,7 @@ func TestAPICreate(t *testing.T) {
 		},
 	}
 
-	handler := APICreate(s)
+	handler := APICreate(s, 48*time.Hour, 168*time.Hour)
 
 	router := chi.NewRouter()
 	router.Post("/api", handler)
@@ -37,6 +50,86 @@ func TestAPICreate(t *testing.T) {
 	assert.Contains(t, w.Body.String(), `"private":true`)
 }
 
+func TestAPICreateName(t *testing.T) {
+	s := &storeMock{
+		PutHookFunc: func(hook *models.Hook) error {
+			if hook.Name == "taken" {
+				return storage.ErrHookExists
+			}
+
+			assert.Equal(t, "stripe-staging", hook.Name)
+
+			return nil
+		},
+	}
+
+	handler := APICreate(s, 48*time.Hour, 168*time.Hour)
+
+	router := chi.NewRouter()
+	router.Post("/api", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/api", "name=stripe-staging")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"stripe-staging"`)
+
+	tbl := map[string]int{
+		"name=taken":       http.StatusConflict,
+		"name=api":         http.StatusBadRequest,
+		"name=favicon.ico": http.StatusBadRequest,
+		"name=Stripe":      http.StatusBadRequest,
+		"name=a%2Fb":       http.StatusBadRequest,
+	}
+
+	for body, code := range tbl {
+		w, err = testRequest(router, http.MethodPost, "/api", body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, code, w.Code, body)
+	}
+
+	assert.Len(t, s.PutHookCalls(), 2)
+}
+
+func TestAPICreateTTL(t *testing.T) {
+	s := &storeMock{
+		PutHookFunc: func(hook *models.Hook) error {
+			assert.Equal(t, 10*time.Minute, hook.Expires.Sub(hook.Created))
+
+			return nil
+		},
+	}
+
+	handler := APICreate(s, 48*time.Hour, 168*time.Hour)
+
+	router := chi.NewRouter()
+	router.Post("/api", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/api", "ttl=10m&private=true")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=600")
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "HttpOnly")
+
+	// public hooks keep no access cookie
+	w, err = testRequest(router, http.MethodPost, "/api", "ttl=10m")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+
+	for _, ttl := range []string{"1s", "169h", "week"} {
+		w, err = testRequest(router, http.MethodPost, "/api", "ttl="+ttl)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, w.Code, ttl)
+	}
+
+	assert.Len(t, s.PutHookCalls(), 2)
+}
+
 func TestAPICreateError(t *testing.T) {
 	s := &storeMock{
 		PutHookFunc: func(hook *models.Hook) error {
@@ -44,7 +137,7 @@ func TestAPICreateError(t *testing.T) {
 		},
 	}
 
-	handler := APICreate(s)
+	handler := APICreate(s, 48*time.Hour, 168*time.Hour)
 
 	router := chi.NewRouter()
 	router.Post("/api", handler)
@@ -65,24 +158,26 @@ func TestAPIHookCases(t *testing.T) {
 		method    string
 		query     string
 		body      string
-		formData  map[string]string
-		queryData map[string]string
+		formData  url.Values
+		queryData url.Values
 	}{
 		{
 			name:   "get",
 			method: http.MethodGet,
-			query:  "?param=value&single",
-			queryData: map[string]string{
-				"param":  "value",
-				"single": "",
+			query:  "?param=value&single&multi=1&multi=2",
+			queryData: url.Values{
+				"param":  {"value"},
+				"single": {""},
+				"multi":  {"1", "2"},
 			},
 		},
 		{
 			name:   "post",
 			method: http.MethodPost,
-			body:   "private=true",
-			formData: map[string]string{
-				"private": "true",
+			body:   "private=true&tags=a&tags=b",
+			formData: url.Values{
+				"private": {"true"},
+				"tags":    {"a", "b"},
 			},
 		},
 		{
@@ -113,9 +208,9 @@ func TestAPIHookCases(t *testing.T) {
 					assert.Equal(t, c.method, req.Method)
 					assert.Equal(t, strings.TrimPrefix(c.query, "?"), req.Query)
 
-					switch req.Headers["Content-Type"] {
+					switch req.Headers.Get("Content-Type") {
 					case "application/json":
-						assert.Equal(t, c.body, req.Body)
+						assert.Equal(t, c.body, string(req.Body))
 					case "application/x-www-form-urlencoded":
 						assert.Equal(t, c.formData, req.FormData)
 					default:
@@ -126,7 +221,7 @@ func TestAPIHookCases(t *testing.T) {
 				},
 			}
 
-			handler := APIHook(s)
+			handler := APIHook(s, &publisherMock{PublishFunc: func(hook string, req *models.Request) {}}, &replayerMock{}, &notifierMock{NotifyFunc: func(hook *models.Hook, req *models.Request) {}}, 1024)
 
 			router := chi.NewRouter()
 			router.Handle("/{hook}", handler)
@@ -139,6 +234,1256 @@ func TestAPIHookCases(t *testing.T) {
 	}
 }
 
+func TestAPIHookSubpath(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			assert.Equal(t, "foo", name)
+
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		PutRequestFunc: func(hook string, req *models.Request) error {
+			assert.Equal(t, "foo", hook)
+			assert.Equal(t, "/foo/github/events", req.Path)
+			assert.Equal(t, "x=1", req.Query)
+
+			return nil
+		},
+	}
+
+	p := &publisherMock{
+		PublishFunc: func(hook string, req *models.Request) {
+			assert.Equal(t, "foo", hook)
+		},
+	}
+
+	n := &notifierMock{
+		NotifyFunc: func(hook *models.Hook, req *models.Request) {
+			assert.Equal(t, "foo", hook.Name)
+			assert.Equal(t, "/foo/github/events", req.Path)
+		},
+	}
+
+	handler := APIHook(s, p, &replayerMock{}, n, 1024)
+
+	router := chi.NewRouter()
+	router.Handle("/{hook}", handler)
+	router.Handle("/{hook}/*", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/foo/github/events?x=1", `{"action": "opened"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, s.PutRequestCalls(), 1)
+	assert.Len(t, p.PublishCalls(), 1)
+	assert.Len(t, n.NotifyCalls(), 1)
+}
+
+func TestAPIHookResponse(t *testing.T) {
+	var stored *models.Request
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+				Response: &models.Response{
+					Status:      http.StatusAccepted,
+					ContentType: "text/plain",
+					Headers:     map[string]string{"X-Foo": "bar"},
+					Body:        "{{ .Method }} {{ .Path }}",
+				},
+			}, nil
+		},
+		PutRequestFunc: func(hook string, req *models.Request) error {
+			stored = req
+			return nil
+		},
+	}
+
+	handler := APIHook(s, &publisherMock{PublishFunc: func(hook string, req *models.Request) {}}, &replayerMock{}, &notifierMock{NotifyFunc: func(hook *models.Hook, req *models.Request) {}}, 1024)
+
+	router := chi.NewRouter()
+	router.Handle("/{hook}/*", handler)
+
+	w, err := testRequest(router, http.MethodPut, "/foo/bar", `{"foo": "bar"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "bar", w.Header().Get("X-Foo"))
+	assert.Equal(t, "PUT /foo/bar", w.Body.String())
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "sandbox", w.Header().Get("Content-Security-Policy"))
+
+	// the sent response is recorded with the request
+	require.NotNil(t, stored.Mock)
+	assert.Equal(t, http.StatusAccepted, stored.Mock.Status)
+	assert.Equal(t, "PUT /foo/bar", string(stored.Mock.Body))
+}
+
+func TestAPIHookForward(t *testing.T) {
+	var stored *models.Request
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:     name,
+				Response: &models.Response{Status: http.StatusOK}, // forwarding takes precedence
+				Forward:  &models.Forward{URL: "http://localhost:3000", Timeout: 500},
+			}, nil
+		},
+		PutRequestFunc: func(hook string, req *models.Request) error {
+			stored = req
+			return nil
+		},
+	}
+
+	f := &replayerMock{
+		ReplayFunc: func(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error) {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(500*time.Millisecond), deadline, 100*time.Millisecond)
+			assert.Equal(t, "http://localhost:3000", target)
+
+			if req.Subpath(hook) == "/down" {
+				return models.NewFailedReply(target, errors.New("connection refused"), 0), nil
+			}
+
+			return &models.Reply{
+				Status:  http.StatusCreated,
+				Headers: http.Header{"X-Upstream": {"yes"}, "Connection": {"close"}, "Content-Security-Policy": {"default-src *"}},
+				Body:    []byte("created"),
+			}, nil
+		},
+	}
+
+	handler := APIHook(s, &publisherMock{PublishFunc: func(hook string, req *models.Request) {}}, f, &notifierMock{NotifyFunc: func(hook *models.Hook, req *models.Request) {}}, 16)
+
+	router := chi.NewRouter()
+	router.Handle("/{hook}/*", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/foo/orders", `{"foo": "bar"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "yes", w.Header().Get("X-Upstream"))
+	assert.Empty(t, w.Header().Get("Connection"))
+	assert.Equal(t, "sandbox", w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "created", w.Body.String())
+	assert.Equal(t, http.StatusCreated, stored.Upstream.Status)
+
+	w, err = testRequest(router, http.MethodPost, "/foo/down", `{"foo": "bar"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "connection refused")
+	assert.Equal(t, "connection refused", stored.Upstream.Error)
+
+	w, err = testRequest(router, http.MethodPost, "/foo/orders", `{"foo": "bar", "baz": "qux"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, errBodyTooLarge.Error(), stored.Upstream.Error)
+	assert.Len(t, f.ReplayCalls(), 2)
+
+	// requests forwarded by hooks are not forwarded again
+	req := httptest.NewRequest(http.MethodPost, "/foo/orders", strings.NewReader("{}"))
+	req.Header.Set("Via", "1.1 proxy, 1.1 hooks")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusLoopDetected, w.Code)
+	assert.Len(t, f.ReplayCalls(), 2)
+	assert.Len(t, s.PutRequestCalls(), 3)
+}
+
+func TestAPIHookCookies(t *testing.T) {
+	var stored *models.Request
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name, Forward: &models.Forward{URL: "http://localhost:3000"}}, nil
+		},
+		PutRequestFunc: func(hook string, req *models.Request) error {
+			stored = req
+			return nil
+		},
+	}
+
+	var forwarded []string
+
+	f := &replayerMock{
+		ReplayFunc: func(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error) {
+			forwarded = append(forwarded, req.Headers.Get("Cookie"))
+			return &models.Reply{Status: http.StatusOK}, nil
+		},
+	}
+
+	handler := APIHook(s, &publisherMock{PublishFunc: func(hook string, req *models.Request) {}}, f, &notifierMock{NotifyFunc: func(hook *models.Hook, req *models.Request) {}}, 1024)
+
+	router := chi.NewRouter()
+	router.Handle("/{hook}", handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+	req.Header.Add("Cookie", "session=sid; theme=dark; hook_foo=secret")
+	req.Header.Add("Cookie", "hook_bar=secret; lang=en")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"theme=dark; lang=en"}, stored.Headers.Values("Cookie"))
+
+	// requests with app cookies only are recorded without cookies
+	req = httptest.NewRequest(http.MethodGet, "/foo", nil)
+	req.Header.Set("Cookie", "session=sid; hook_foo=secret")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, stored.Headers, "Cookie")
+	assert.Equal(t, []string{"theme=dark; lang=en", ""}, forwarded)
+}
+
+func TestAPIGetHook(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			if name != "foo" {
+				return nil, nil
+			}
+
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+	}
+
+	handler := APIGetHook(s)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"foo"`)
+
+	w, err = testRequest(router, http.MethodGet, "/bar", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPIGetHookPrivate(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:    name,
+				Private: true,
+				Secret:  "secret",
+			}, nil
+		},
+	}
+
+	handler := APIGetHook(s)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPIRequests(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		RequestsPageFunc: func(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+			assert.Equal(t, "foo", hook)
+
+			if before == "" {
+				assert.Equal(t, 1, limit)
+				assert.True(t, match(&models.Request{Method: http.MethodPost}))
+				assert.False(t, match(&models.Request{Method: http.MethodGet}))
+
+				return []*models.Request{{Name: "req3", Method: http.MethodPost}}, "req3", nil
+			}
+
+			if before != "req3" {
+				return nil, "", storage.ErrInvalidCursor
+			}
+
+			return []*models.Request{{Name: "req1", Method: http.MethodPost}}, "", nil
+		},
+	}
+
+	handler := APIRequests(s)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo?method=post&limit=1", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Name":"req3"`)
+	assert.Contains(t, w.Body.String(), `"next":"req3"`)
+
+	w, err = testRequest(router, http.MethodGet, "/foo?method=post&before=req3", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Name":"req1"`)
+	assert.Contains(t, w.Body.String(), `"next":""`)
+
+	w, err = testRequest(router, http.MethodGet, "/foo?before=malformed", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, err = testRequest(router, http.MethodGet, "/foo?since=today", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIRequest(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		RequestFunc: func(hook string, name string) (*models.Request, error) {
+			assert.Equal(t, "foo", hook)
+
+			if name != "bar" {
+				return nil, nil
+			}
+
+			return &models.Request{Name: name}, nil
+		},
+	}
+
+	handler := APIRequest(s)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}/{request}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo/bar", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Name":"bar"`)
+
+	w, err = testRequest(router, http.MethodGet, "/foo/baz", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Request is not found"}`, w.Body.String())
+}
+
+func TestAPINextRequestStored(t *testing.T) {
+	now := time.Now()
+
+	requests := []*models.Request{
+		{Name: "req4", Method: http.MethodPost, Created: now},
+		{Name: "req3", Method: http.MethodPost, Created: now.Add(-time.Minute)},
+		{Name: "req2", Method: http.MethodGet, Created: now.Add(-2 * time.Minute)},
+		{Name: "req1", Method: http.MethodPost, Created: now.Add(-3 * time.Minute)},
+	}
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		RequestFunc: func(hook string, name string) (*models.Request, error) {
+			for _, req := range requests {
+				if req.Name == name {
+					return req, nil
+				}
+			}
+
+			return nil, nil
+		},
+		NextRequestFunc: func(hook string, after string, match func(req *models.Request) bool) (*models.Request, error) {
+			for i := len(requests) - 1; i >= 0; i-- {
+				if requests[i].Name != after {
+					continue
+				}
+
+				for j := i - 1; j >= 0; j-- {
+					if match(requests[j]) {
+						return requests[j], nil
+					}
+				}
+			}
+
+			return nil, nil
+		},
+	}
+	sub := &subscriberMock{
+		SubscribeFunc: func(hook string) (<-chan *models.Request, func()) {
+			return make(chan *models.Request), func() {}
+		},
+	}
+
+	handler := APINextRequest(s, sub)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo?after=req1", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Name":"req2"`)
+
+	w, err = testRequest(router, http.MethodGet, "/foo?after=req1&method=post", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Name":"req3"`)
+
+	w, err = testRequest(router, http.MethodGet, "/foo?after=req0", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, err = testRequest(router, http.MethodGet, "/foo?after=req4&timeout=10ms", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w, err = testRequest(router, http.MethodGet, "/foo?timeout=1h", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPINextRequestWait(t *testing.T) {
+	ch := make(chan *models.Request, 2)
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+	}
+	sub := &subscriberMock{
+		SubscribeFunc: func(hook string) (<-chan *models.Request, func()) {
+			assert.Equal(t, "foo", hook)
+
+			ch <- &models.Request{Name: "req1", Method: http.MethodGet}
+			ch <- &models.Request{Name: "req2", Method: http.MethodPut}
+
+			return ch, func() {}
+		},
+	}
+
+	handler := APINextRequest(s, sub)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo?method=put&timeout=1s", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Name":"req2"`)
+}
+
+func TestAPIHookBody(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		PutRequestFunc: func(hook string, req *models.Request) error {
+			assert.Equal(t, "fizz=buzz&foo=bar", string(req.Body))
+			assert.Equal(t, url.Values{"fizz": {"buzz"}, "foo": {"bar"}}, req.FormData)
+
+			return nil
+		},
+	}
+
+	handler := APIHook(s, &publisherMock{PublishFunc: func(hook string, req *models.Request) {}}, &replayerMock{}, &notifierMock{NotifyFunc: func(hook *models.Hook, req *models.Request) {}}, 1024)
+
+	router := chi.NewRouter()
+	router.Handle("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/foo", "fizz=buzz&foo=bar")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, s.PutRequestCalls(), 1)
+}
+
+func TestAPIRequestSnippets(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		RequestFunc: func(hook string, name string) (*models.Request, error) {
+			return &models.Request{
+				Name:    name,
+				Method:  http.MethodPost,
+				Path:    "/foo",
+				Body:    []byte("a=1"),
+				Headers: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			}, nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/{hook}/{request}", APIRequestSnippets(s, "http://localhost:8080"))
+
+	w, err := testRequest(router, http.MethodGet, "/foo/bar", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"httpie"`)
+	assert.Contains(t, w.Body.String(), `"name":"fetch"`)
+
+	w, err = testRequest(router, http.MethodGet, "/foo/bar?lang=curl", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "curl -X POST 'http://localhost:8080/foo' \\\n  -H 'Content-Type: application/x-www-form-urlencoded' \\\n  --data-binary 'a=1'", w.Body.String())
+
+	w, err = testRequest(router, http.MethodGet, "/foo/bar?lang=ruby", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIRequestBody(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		RequestFunc: func(hook string, name string) (*models.Request, error) {
+			return &models.Request{
+				Name:          name,
+				Body:          []byte{0x89, 0x50, 0x4e, 0x47},
+				BodySize:      8,
+				BodyTruncated: true,
+				Headers:       http.Header{"Content-Type": {"image/png"}},
+			}, nil
+		},
+	}
+
+	handler := APIRequestBody(s)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}/{request}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo/bar", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="bar"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "8", w.Header().Get(headerOriginalSize))
+	assert.Equal(t, []byte{0x89, 0x50, 0x4e, 0x47}, w.Body.Bytes())
+}
+
+func TestAPIRequestFile(t *testing.T) {
+	body := "--xyz\r\n" +
+		"Content-Disposition: form-data; name=\"upload\"; filename=\"hello.txt\"\r\n" +
+		"Content-Type: text/plain\r\n\r\n" +
+		"hello world\r\n" +
+		"--xyz--\r\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/foo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+
+	captured := models.NewRequest(req, 1024)
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+		RequestFunc: func(hook string, name string) (*models.Request, error) {
+			return captured, nil
+		},
+	}
+
+	handler := APIRequestFile(s)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}/{request}/{file}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo/bar/0", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=hello.txt`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "hello world", w.Body.String())
+
+	w, err = testRequest(router, http.MethodGet, "/foo/bar/1", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, err = testRequest(router, http.MethodGet, "/foo/bar/x", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPIReplayRequest(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:   name,
+				Secret: "secret",
+			}, nil
+		},
+		RequestFunc: func(hook string, name string) (*models.Request, error) {
+			return &models.Request{
+				Name:   name,
+				Method: http.MethodPost,
+				Path:   "/foo/orders",
+			}, nil
+		},
+		UpdateRequestFunc: func(hook string, req *models.Request) error {
+			assert.Equal(t, "foo", hook)
+			assert.Len(t, req.Replays, 1)
+
+			return nil
+		},
+	}
+
+	rp := &replayerMock{
+		ReplayFunc: func(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error) {
+			switch target {
+			case "invalid":
+				return nil, proxy.ErrInvalidURL
+			case "http://169.254.169.254":
+				return nil, fmt.Errorf("dial tcp: %w", network.ErrForbiddenAddress)
+			}
+
+			assert.Equal(t, "http://localhost:3000", target)
+			assert.Equal(t, "foo", hook)
+			assert.Equal(t, "bar", req.Name)
+
+			return &models.Reply{
+				URL:    target + req.Subpath(hook),
+				Status: http.StatusAccepted,
+			}, nil
+		},
+	}
+
+	handler := APIReplayRequest(s, rp)
+
+	router := chi.NewRouter()
+	router.Post("/{hook}/{request}", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/foo/bar?secret=secret", "url=http://localhost:3000")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"URL":"http://localhost:3000/orders"`)
+	assert.Contains(t, w.Body.String(), `"Status":202`)
+	assert.Len(t, s.UpdateRequestCalls(), 1)
+
+	w, err = testRequest(router, http.MethodPost, "/foo/bar?secret=secret", "url=invalid")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, err = testRequest(router, http.MethodPost, "/foo/bar?secret=secret", "url=")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// targets in the local network of the server are refused and not recorded
+	w, err = testRequest(router, http.MethodPost, "/foo/bar?secret=secret", "url=http://169.254.169.254")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, s.UpdateRequestCalls(), 1)
+
+	// visitors of public hook could not replay its requests
+	w, err = testRequest(router, http.MethodPost, "/foo/bar", "url=http://localhost:3000")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, s.UpdateRequestCalls(), 1)
+}
+
+func TestAPIUpdateResponse(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:   name,
+				Secret: "secret",
+			}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			assert.Equal(t, "foo", hook.Name)
+			assert.Equal(t, &models.Response{
+				Status:      http.StatusServiceUnavailable,
+				ContentType: "application/json",
+				Headers:     map[string]string{"Retry-After": "120", "X-Foo": "bar"},
+				Body:        `{"ok": false}`,
+				Delay:       150,
+			}, hook.Response)
+
+			return nil
+		},
+	}
+
+	handler := APIUpdateResponse(s)
+
+	router := chi.NewRouter()
+	router.Post("/{hook}", handler)
+
+	form := url.Values{}
+	form.Set("status", "503")
+	form.Set("content_type", "application/json")
+	form.Set("headers", "retry-after: 120\nX-Foo: bar\n")
+	form.Set("body", `{"ok": false}`)
+	form.Set("delay", "150")
+
+	w, err := testRequest(router, http.MethodPost, "/foo?secret=secret", form.Encode())
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+}
+
+func TestAPIUpdateResponseInvalid(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:   name,
+				Secret: "secret",
+			}, nil
+		},
+	}
+
+	handler := APIUpdateResponse(s)
+
+	router := chi.NewRouter()
+	router.Post("/{hook}", handler)
+
+	form := url.Values{}
+	form.Set("status", "1000")
+
+	w, err := testRequest(router, http.MethodPost, "/foo?secret=secret", form.Encode())
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIDeleteResponse(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:     name,
+				Secret:   "secret",
+				Response: &models.Response{Status: http.StatusOK},
+			}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			assert.Nil(t, hook.Response)
+
+			return nil
+		},
+	}
+
+	handler := APIDeleteResponse(s)
+
+	router := chi.NewRouter()
+	router.Delete("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodDelete, "/foo?secret=secret", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+}
+
+func TestAPIUpdateForward(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:   name,
+				Secret: "secret",
+			}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			assert.Equal(t, &models.Forward{
+				URL:     "http://localhost:3000/webhooks",
+				Timeout: 2000,
+			}, hook.Forward)
+
+			return nil
+		},
+	}
+
+	handler := APIUpdateForward(s)
+
+	router := chi.NewRouter()
+	router.Post("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/foo?secret=secret", "url=http%3A%2F%2Flocalhost%3A3000%2Fwebhooks&timeout=2000")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+
+	w, err = testRequest(router, http.MethodPost, "/foo?secret=secret", "url=localhost")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+}
+
+func TestAPIDeleteForward(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:    name,
+				Secret:  "secret",
+				Forward: &models.Forward{URL: "http://localhost:3000"},
+			}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			assert.Nil(t, hook.Forward)
+
+			return nil
+		},
+	}
+
+	handler := APIDeleteForward(s)
+
+	router := chi.NewRouter()
+	router.Delete("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodDelete, "/foo?secret=secret", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+}
+
+func TestAPIHookSignature(t *testing.T) {
+	var stored *models.Request
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:      name,
+				Signature: &models.Signature{Provider: models.ProviderGitHub, Secret: "secret"},
+			}, nil
+		},
+		PutRequestFunc: func(hook string, req *models.Request) error {
+			stored = req
+			return nil
+		},
+	}
+
+	handler := APIHook(s, &publisherMock{PublishFunc: func(hook string, req *models.Request) {}}, &replayerMock{}, &notifierMock{NotifyFunc: func(hook *models.Hook, req *models.Request) {}}, 16)
+
+	router := chi.NewRouter()
+	router.Handle("/{hook}", handler)
+
+	body := `{"action": "opened", "number": 1347}`
+
+	// the signature is computed over the whole body while only 16 bytes are stored
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte(body))
+
+	for sig, valid := range map[string]bool{
+		"sha256=" + hex.EncodeToString(mac.Sum(nil)): true,
+		"sha256=" + strings.Repeat("0", 64):          false,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/foo", strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", sig)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, stored.BodyTruncated)
+		assert.Equal(t, valid, stored.Signature.Valid, sig)
+		assert.Equal(t, models.ProviderGitHub, stored.Signature.Provider)
+
+		if !valid {
+			assert.Contains(t, stored.Signature.Reason, "does not match")
+		}
+	}
+}
+
+func TestAPIUpdateSignature(t *testing.T) {
+	var updated *models.Signature
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			hook := &models.Hook{
+				Name:   name,
+				Secret: "secret",
+			}
+
+			if name == "bar" {
+				hook.Signature = &models.Signature{Provider: models.ProviderGitHub, Secret: "old-key"}
+			}
+
+			return hook, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			updated = hook.Signature
+			return nil
+		},
+	}
+
+	handler := APIUpdateSignature(s)
+
+	router := chi.NewRouter()
+	router.Post("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/foo?secret=secret", "provider=hmac&secret=sign-key&header=X-Hmac")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+	assert.Equal(t, &models.Signature{
+		Provider: models.ProviderHMAC,
+		Secret:   "sign-key",
+		Header:   "X-Hmac",
+	}, updated)
+
+	// the signing secret is never shown back
+	assert.NotContains(t, w.Body.String(), "sign-key")
+	assert.Contains(t, w.Body.String(), `"secret_set":true`)
+
+	for _, body := range []string{"provider=paypal&secret=secret", "provider=github", "provider=stripe&secret=s&tolerance=x"} {
+		w, err = testRequest(router, http.MethodPost, "/foo?secret=secret", body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	assert.Len(t, s.UpdateHookCalls(), 1)
+
+	// a blank secret keeps the current one
+	w, err = testRequest(router, http.MethodPost, "/bar?secret=secret", "provider=stripe&tolerance=60")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &models.Signature{
+		Provider:  models.ProviderStripe,
+		Secret:    "old-key",
+		Tolerance: 60,
+	}, updated)
+}
+
+func TestAPIGetHookSignatureSecret(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:      name,
+				Secret:    "secret",
+				Signature: &models.Signature{Provider: models.ProviderGitHub, Secret: "sign-key"},
+			}, nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", APIGetHook(s))
+
+	w, err := testRequest(router, http.MethodGet, "/foo?secret=secret", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"signature":{"provider":"github","secret_set":true}`)
+	assert.NotContains(t, w.Body.String(), "sign-key")
+}
+
+func TestAPIDeleteSignature(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:      name,
+				Secret:    "secret",
+				Signature: &models.Signature{Provider: models.ProviderSlack, Secret: "secret"},
+			}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			assert.Nil(t, hook.Signature)
+
+			return nil
+		},
+	}
+
+	handler := APIDeleteSignature(s)
+
+	router := chi.NewRouter()
+	router.Delete("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodDelete, "/foo?secret=secret", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+}
+
+func TestAPIUpdateNotifications(t *testing.T) {
+	var updated *models.Notifications
+
+	s := &storeMock{
+		SessionFunc: testSession,
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:          name,
+				Secret:        "secret",
+				Owner:         "team",
+				Notifications: &models.Notifications{Email: []string{"team@example.com"}},
+			}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			updated = hook.Notifications
+			return nil
+		},
+	}
+
+	n := &notifierMock{
+		EmailEnabledFunc: func() bool {
+			return true
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Post("/{hook}", APIUpdateNotifications(s, n))
+
+	body := "webhook=" + url.QueryEscape("https://example.com/events") + "&email=" + url.QueryEscape("john@example.com, jane@example.com") + "&rate=5"
+	w := sessionRequest(router, http.MethodPost, "/foo", body, "sid")
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, s.UpdateHookCalls(), 1)
+	assert.Equal(t, &models.Notifications{
+		Webhook: "https://example.com/events",
+		Email:   []string{"john@example.com", "jane@example.com"},
+		Rate:    5,
+	}, updated)
+
+	for _, body := range []string{"rate=5", "webhook=localhost", "slack=http://localhost&rate=x", "email=john"} {
+		w = sessionRequest(router, http.MethodPost, "/foo", body, "sid")
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	// recipients are chosen by the owner only, other clients keep them
+	w = sessionRequest(router, http.MethodPost, "/foo?secret=secret", "email=spam@example.com", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = sessionRequest(router, http.MethodPost, "/foo?secret=secret", "webhook="+url.QueryEscape("https://example.com/events"), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"team@example.com"}, updated.Email)
+	assert.Len(t, s.UpdateHookCalls(), 2)
+
+	// emails could not be sent without SMTP server
+	n.EmailEnabledFunc = func() bool {
+		return false
+	}
+
+	w = sessionRequest(router, http.MethodPost, "/foo", "email=john@example.com", "sid")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 2)
+}
+
+func TestAPIDeleteNotifications(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:          name,
+				Secret:        "secret",
+				Notifications: &models.Notifications{Webhook: "https://example.com/events"},
+			}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			assert.Nil(t, hook.Notifications)
+
+			return nil
+		},
+	}
+
+	handler := APIDeleteNotifications(s)
+
+	router := chi.NewRouter()
+	router.Delete("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodDelete, "/foo?secret=secret", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Len(t, s.UpdateHookCalls(), 1)
+}
+
+func TestAPIExportHAR(t *testing.T) {
+	now := time.Now()
+	requests := map[string]*models.Request{
+		"a": {Name: "a", Method: http.MethodPost, Path: "/foo", Headers: http.Header{}, Created: now.Add(-time.Minute)},
+		"b": {Name: "b", Method: http.MethodGet, Path: "/foo", Headers: http.Header{}, Created: now},
+	}
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name}, nil
+		},
+		RequestFunc: func(hook string, name string) (*models.Request, error) {
+			return requests[name], nil
+		},
+		RequestsPageFunc: func(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+			assert.Equal(t, maxLimit, limit)
+
+			var result []*models.Request
+			for _, req := range []*models.Request{requests["b"], requests["a"]} {
+				if match(req) {
+					result = append(result, req)
+				}
+			}
+
+			return result, "", nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/{hook}/har", APIExportHAR(s, "http://localhost:8080", "1.0.0"))
+
+	tbl := map[string][]string{
+		"/foo/har":                     {http.MethodPost, http.MethodGet},
+		"/foo/har?method=get":          {http.MethodGet},
+		"/foo/har?request=b&request=a": {http.MethodPost, http.MethodGet},
+	}
+
+	for address, methods := range tbl {
+		w, err := testRequest(router, http.MethodGet, address, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code, address)
+		assert.Equal(t, `attachment; filename="foo.har"`, w.Header().Get("Content-Disposition"))
+
+		var doc har.HAR
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		assert.Equal(t, "1.0.0", doc.Log.Creator.Version)
+		assert.Len(t, doc.Log.Entries, len(methods), address)
+
+		for i, method := range methods {
+			assert.Equal(t, method, doc.Log.Entries[i].Request.Method, address)
+			assert.Equal(t, "http://localhost:8080/foo", doc.Log.Entries[i].Request.URL)
+		}
+	}
+
+	w, err := testRequest(router, http.MethodGet, "/foo/har?request=missing", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPIImport(t *testing.T) {
+	var stored []*models.Request
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name, Secret: "secret"}, nil
+		},
+		PutRequestFunc: func(hook string, req *models.Request) error {
+			assert.Equal(t, "foo", hook)
+			stored = append(stored, req)
+
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Post("/{hook}/import", APIImport(s, 1024))
+
+	archive := `{"log": {"entries": [
+		{"startedDateTime": "2022-05-01T10:00:00Z", "request": {"method": "GET", "url": "https://example.com/?x=1", "headers": []}},
+		{"startedDateTime": "2022-05-01T10:00:01Z", "request": {"method": "POST", "url": "https://example.com/events",
+			"headers": [{"name": "X-Forwarded-For", "value": "10.0.0.1"}],
+			"postData": {"mimeType": "application/json", "text": "{\"id\":1}"}}}
+	]}}`
+
+	w, err := testRequest(router, http.MethodPost, "/foo/import?secret=secret", "data="+url.QueryEscape(archive))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, stored, 2)
+	assert.JSONEq(t, fmt.Sprintf(`{"imported": 2, "requests": [%q, %q]}`, stored[0].Name, stored[1].Name), w.Body.String())
+
+	assert.Equal(t, "/foo", stored[0].Path)
+	assert.Equal(t, "x=1", stored[0].Query)
+	assert.Equal(t, "", stored[0].RemoteAddr)
+	assert.Equal(t, time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC), stored[0].Created)
+	assert.Equal(t, "/foo/events", stored[1].Path)
+	assert.Equal(t, "10.0.0.1", stored[1].RemoteAddr)
+	assert.Equal(t, `{"id":1}`, string(stored[1].Body))
+	assert.Less(t, stored[0].Name, stored[1].Name)
+
+	stored = nil
+	cmd := `curl -X PUT 'http://localhost:8080/old/items?id=1' -H 'Content-Type: text/plain' --data-raw 'hello' -u user:secret`
+
+	w, err = testRequest(router, http.MethodPost, "/foo/import?secret=secret", "data="+url.QueryEscape(cmd))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, stored, 1)
+	assert.Equal(t, http.MethodPut, stored[0].Method)
+	assert.Equal(t, "/foo/old/items", stored[0].Path)
+	assert.Equal(t, "hello", string(stored[0].Body))
+	assert.Equal(t, "Basic dXNlcjpzZWNyZXQ=", stored[0].Headers.Get("Authorization"))
+
+	// requests exported from the hook are imported back as is
+	stored = nil
+
+	w, err = testRequest(router, http.MethodPost, "/foo/import?secret=secret", "data="+url.QueryEscape("curl http://localhost:8080/foo/events"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, stored, 1)
+	assert.Equal(t, "/foo/events", stored[0].Path)
+
+	for _, data := range []string{"", "wget http://localhost", "curl -d @file http://localhost", "{\"log\": 1}"} {
+		w, err = testRequest(router, http.MethodPost, "/foo/import?secret=secret", "data="+url.QueryEscape(data))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, w.Code, data)
+	}
+
+	// visitors of public hook could not import requests into it
+	stored = nil
+
+	w, err = testRequest(router, http.MethodPost, "/foo/import", "data="+url.QueryEscape("curl http://localhost:8080/foo/events"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, stored)
+}
+
 func TestAPIStats(t *testing.T) {
 	s := &storeMock{
 		CountersFunc: func() (map[string]int, error) {
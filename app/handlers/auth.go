@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/storage"
+)
+
+const (
+	sessionCookie = "session"
+	sessionTTL    = 30 * 24 * time.Hour
+)
+
+// APISignUp handle creation of a new user account, the user is signed in
+func APISignUp(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		user, err := models.NewUser(r.PostForm.Get("username"), r.PostForm.Get("password"))
+		if errors.Is(err, models.ErrInvalidUsername) || errors.Is(err, models.ErrInvalidPassword) {
+			renderError(w, r, fmt.Errorf("%w: %s", errBadRequest, err))
+			return
+		}
+
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if err := s.PutUser(user); err != nil {
+			if errors.Is(err, storage.ErrUserExists) {
+				err = fmt.Errorf("%w: %s", errConflict, err)
+			}
+
+			renderError(w, r, err)
+
+			return
+		}
+
+		if err := signIn(w, s, user.Name); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]string{"name": user.Name})
+	}
+}
+
+// APISignIn handle sign in by username and password
+func APISignIn(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		user, err := s.User(r.PostForm.Get("username"))
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		// the password is checked for unknown users too, see CheckPassword
+		if !user.CheckPassword(r.PostForm.Get("password")) {
+			renderError(w, r, fmt.Errorf("%w: wrong username or password", errUnauthorized))
+			return
+		}
+
+		if err := signIn(w, s, user.Name); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.JSON(w, r, map[string]string{"name": user.Name})
+	}
+}
+
+// APISignOut handle sign out of the current session
+func APISignOut(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie(sessionCookie); err == nil {
+			if err := s.DeleteSession(c.Value); err != nil {
+				renderError(w, r, err)
+				return
+			}
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		render.NoContent(w, r)
+	}
+}
+
+// APIUserHooks handle listing of hooks owned by the signed in user, newest first
+func APIUserHooks(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := currentUser(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if user == "" {
+			renderError(w, r, errUnauthorized)
+			return
+		}
+
+		hooks, err := s.UserHooks(user)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		for _, hook := range hooks {
+			hook.Signature = hook.Signature.Masked()
+		}
+
+		render.JSON(w, r, map[string]interface{}{
+			"hooks": hooks,
+		})
+	}
+}
+
+// APIRenameHook handle renaming of a hook owned by the signed in user
+func APIRenameHook(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := ownedHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		name := r.PostForm.Get("name")
+		if err := models.ValidateName(name); err != nil {
+			renderError(w, r, fmt.Errorf("%w: %s", errBadRequest, err))
+			return
+		}
+
+		if err := s.RenameHook(hook.Name, name); err != nil {
+			if errors.Is(err, storage.ErrHookExists) {
+				err = fmt.Errorf("%w: %s", errConflict, err)
+			}
+
+			renderError(w, r, err)
+
+			return
+		}
+
+		hook.Name = name
+		hook.Signature = hook.Signature.Masked()
+
+		render.JSON(w, r, hook)
+	}
+}
+
+// APIDeleteHook handle deletion of a hook owned by the signed in user
+func APIDeleteHook(s store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := ownedHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if err := s.DeleteHook(hook.Name); err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		render.NoContent(w, r)
+	}
+}
+
+// signIn starts a new session of the user
+func signIn(w http.ResponseWriter, s store, user string) error {
+	session, err := models.NewSession(user, sessionTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := s.PutSession(session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session.ID,
+		Path:     "/",
+		MaxAge:   int(sessionTTL.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// currentUser returns name of the signed in user, empty for anonymous requests
+func currentUser(s store, r *http.Request) (string, error) {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil || c.Value == "" {
+		return "", nil
+	}
+
+	session, err := s.Session(c.Value)
+	if err != nil || session == nil {
+		return "", err
+	}
+
+	return session.User, nil
+}
+
+// ownsHook reports whether the hook is owned by the signed in user
+func ownsHook(s store, r *http.Request, hook *models.Hook) (bool, error) {
+	if hook.Owner == "" {
+		return false, nil
+	}
+
+	user, err := currentUser(s, r)
+	if err != nil {
+		return false, err
+	}
+
+	return user == hook.Owner, nil
+}
+
+// canAccess reports whether the hook is accessible by the request by the secret or by the owner session
+func canAccess(s store, r *http.Request, hook *models.Hook) (bool, error) {
+	if checkAccess(r, hook) {
+		return true, nil
+	}
+
+	return ownsHook(s, r, hook)
+}
+
+// canConfigure reports whether settings of the hook could be changed by the request.
+// It requires the hook secret or the owner session even for public hooks
+func canConfigure(s store, r *http.Request, hook *models.Hook) (bool, error) {
+	if hasSecret(r, hook) {
+		return true, nil
+	}
+
+	return ownsHook(s, r, hook)
+}
+
+// configurableHook returns hook from url if its settings could be changed by client
+func configurableHook(s store, r *http.Request) (*models.Hook, error) {
+	hook, err := accessibleHook(s, r)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := canConfigure(s, r, hook)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: hook settings could be changed by its owner or with its secret", errForbidden)
+	}
+
+	return hook, nil
+}
+
+// ownedHook returns hook from url owned by the signed in user
+func ownedHook(s store, r *http.Request) (*models.Hook, error) {
+	hook, err := s.Hook(chi.URLParam(r, urlParam))
+	if err != nil {
+		return nil, err
+	}
+
+	if hook == nil {
+		return nil, errNotFound
+	}
+
+	owned, err := ownsHook(s, r, hook)
+	if err != nil {
+		return nil, err
+	}
+
+	if !owned {
+		return nil, errNotFound
+	}
+
+	return hook, nil
+}
+
+// stripAppCookies removes session and hook access cookies sent along with requests to hooks,
+// so they are neither recorded nor forwarded to the upstream
+func stripAppCookies(h http.Header) {
+	values := h.Values("Cookie")
+	if len(values) == 0 {
+		return
+	}
+
+	kept := make([]string, 0, len(values))
+
+	for _, value := range values {
+		for _, part := range strings.Split(value, ";") {
+			part = strings.TrimSpace(part)
+			name := strings.TrimSpace(strings.SplitN(part, "=", 2)[0])
+
+			if part == "" || name == sessionCookie || strings.HasPrefix(name, cookiePrefix) {
+				continue
+			}
+
+			kept = append(kept, part)
+		}
+	}
+
+	h.Del("Cookie")
+
+	if len(kept) > 0 {
+		h.Set("Cookie", strings.Join(kept, "; "))
+	}
+}
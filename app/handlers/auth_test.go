@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/storage"
+)
+
+func TestAPISignUp(t *testing.T) {
+	users := map[string]*models.User{}
+
+	s := &storeMock{
+		PutUserFunc: func(user *models.User) error {
+			if _, ok := users[user.Name]; ok {
+				return storage.ErrUserExists
+			}
+
+			users[user.Name] = user
+
+			return nil
+		},
+		PutSessionFunc: func(session *models.Session) error {
+			assert.Equal(t, "team", session.User)
+
+			return nil
+		},
+	}
+
+	handler := APISignUp(s)
+
+	router := chi.NewRouter()
+	router.Post("/api/users", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/api/users", "username=team&password=password")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "session=")
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "HttpOnly")
+	assert.True(t, users["team"].CheckPassword("password"))
+
+	w, err = testRequest(router, http.MethodPost, "/api/users", "username=team&password=password")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	for _, body := range []string{"username=Team+A&password=password", "username=other&password=short"} {
+		w, err = testRequest(router, http.MethodPost, "/api/users", body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestAPISignIn(t *testing.T) {
+	user, err := models.NewUser("team", "password")
+	require.NoError(t, err)
+
+	s := &storeMock{
+		UserFunc: func(name string) (*models.User, error) {
+			if name == user.Name {
+				return user, nil
+			}
+
+			return nil, nil
+		},
+		PutSessionFunc: func(session *models.Session) error {
+			return nil
+		},
+	}
+
+	handler := APISignIn(s)
+
+	router := chi.NewRouter()
+	router.Post("/api/login", handler)
+
+	w, err := testRequest(router, http.MethodPost, "/api/login", "username=team&password=password")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "session=")
+	assert.Len(t, s.PutSessionCalls(), 1)
+
+	for _, body := range []string{"username=team&password=wrong", "username=unknown&password=password"} {
+		w, err = testRequest(router, http.MethodPost, "/api/login", body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, body)
+		assert.Empty(t, w.Header().Get("Set-Cookie"))
+	}
+}
+
+func TestAPISignOut(t *testing.T) {
+	s := &storeMock{
+		DeleteSessionFunc: func(id string) error {
+			assert.Equal(t, "sid", id)
+
+			return nil
+		},
+	}
+
+	w := sessionRequest(APISignOut(s), http.MethodPost, "/api/logout", "", "sid")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=0")
+	assert.Len(t, s.DeleteSessionCalls(), 1)
+}
+
+func TestAPIUserHooks(t *testing.T) {
+	s := &storeMock{
+		SessionFunc: testSession,
+		UserHooksFunc: func(user string) ([]*models.Hook, error) {
+			assert.Equal(t, "team", user)
+
+			return []*models.Hook{{Name: "foo", Owner: user}}, nil
+		},
+	}
+
+	w := sessionRequest(APIUserHooks(s), http.MethodGet, "/api/user/hooks", "", "sid")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"foo"`)
+
+	w = sessionRequest(APIUserHooks(s), http.MethodGet, "/api/user/hooks", "", "")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPIRenameHook(t *testing.T) {
+	s := &storeMock{
+		SessionFunc: testSession,
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name, Owner: "team"}, nil
+		},
+		RenameHookFunc: func(name string, newName string) error {
+			assert.Equal(t, "foo", name)
+
+			if newName == "taken" {
+				return storage.ErrHookExists
+			}
+
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Post("/{hook}/rename", APIRenameHook(s))
+
+	w := sessionRequest(router, http.MethodPost, "/foo/rename", "name=stripe-staging", "sid")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"stripe-staging"`)
+
+	tbl := map[string]int{
+		"name=taken": http.StatusConflict,
+		"name=api":   http.StatusBadRequest,
+		"name=a+b":   http.StatusBadRequest,
+	}
+
+	for body, code := range tbl {
+		w = sessionRequest(router, http.MethodPost, "/foo/rename", body, "sid")
+		assert.Equal(t, code, w.Code, body)
+	}
+
+	w = sessionRequest(router, http.MethodPost, "/foo/rename", "name=bar", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code) // not an owner
+	assert.Len(t, s.RenameHookCalls(), 2)
+}
+
+func TestAPIRenameHookReplay(t *testing.T) {
+	mem := storage.NewMemory()
+
+	require.NoError(t, mem.PutSession(&models.Session{ID: "sid", User: "team", Expires: time.Now().Add(time.Hour)}))
+	require.NoError(t, mem.PutHook(&models.Hook{Name: "foo", Owner: "team", Created: time.Now()}))
+	require.NoError(t, mem.PutRequest("foo", &models.Request{Name: "req", Method: http.MethodPost, Path: "/foo/orders", Created: time.Now()}))
+
+	rp := &replayerMock{
+		ReplayFunc: func(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error) {
+			return &models.Reply{URL: target + req.Subpath(hook), Status: http.StatusOK}, nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Post("/{hook}/rename", APIRenameHook(mem))
+	router.Post("/{hook}/{request}/replay", APIReplayRequest(mem, rp))
+
+	w := sessionRequest(router, http.MethodPost, "/foo/rename", "name=bar", "sid")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// the path of the request follows the new name of the hook
+	w = sessionRequest(router, http.MethodPost, "/bar/req/replay", "url=http://example.com", "sid")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"URL":"http://example.com/orders"`)
+}
+
+func TestAPIDeleteHook(t *testing.T) {
+	s := &storeMock{
+		SessionFunc: testSession,
+		HookFunc: func(name string) (*models.Hook, error) {
+			owner := "team"
+			if name == "other" {
+				owner = "other"
+			}
+
+			return &models.Hook{Name: name, Owner: owner}, nil
+		},
+		DeleteHookFunc: func(name string) error {
+			assert.Equal(t, "foo", name)
+
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Delete("/{hook}", APIDeleteHook(s))
+
+	w := sessionRequest(router, http.MethodDelete, "/foo", "", "sid")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = sessionRequest(router, http.MethodDelete, "/other", "", "sid")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Len(t, s.DeleteHookCalls(), 1)
+}
+
+func TestAPICreateOwned(t *testing.T) {
+	s := &storeMock{
+		SessionFunc: testSession,
+		PutHookFunc: func(hook *models.Hook) error {
+			assert.Equal(t, "team", hook.Owner)
+			assert.True(t, hook.Expires.IsZero())
+
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Post("/api/create", APICreate(s, 48*time.Hour, 168*time.Hour))
+
+	w := sessionRequest(router, http.MethodPost, "/api/create", "private=true", "sid")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"owner":"team"`)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=2592000")
+}
+
+func TestAPIGetHookOwned(t *testing.T) {
+	s := &storeMock{
+		SessionFunc: testSession,
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name, Private: true, Secret: "secret", Owner: "team"}, nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", APIGetHook(s))
+
+	w := sessionRequest(router, http.MethodGet, "/foo", "", "sid")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sessionRequest(router, http.MethodGet, "/foo", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPIConfigurePublicOwned(t *testing.T) {
+	s := &storeMock{
+		SessionFunc: testSession,
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name, Secret: "secret", Owner: "team"}, nil
+		},
+		UpdateHookFunc: func(hook *models.Hook) error {
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Post("/{hook}/forward", APIUpdateForward(s))
+	router.Delete("/{hook}/forward", APIDeleteForward(s))
+	router.Post("/{hook}/response", APIUpdateResponse(s))
+	router.Post("/{hook}/signature", APIUpdateSignature(s))
+	router.Post("/{hook}/notifications", APIUpdateNotifications(s, &notifierMock{}))
+
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodPost, "/foo/forward", "url=http%3A%2F%2Flocalhost%3A3000"},
+		{http.MethodDelete, "/foo/forward", ""},
+		{http.MethodPost, "/foo/response", "status=201"},
+		{http.MethodPost, "/foo/signature", "provider=github&secret=x"},
+		{http.MethodPost, "/foo/notifications", "webhook=http%3A%2F%2Flocalhost%3A3000"},
+	} {
+		// anonymous visitors could read the public hook but not change it
+		w := sessionRequest(router, tc.method, tc.path, tc.body, "")
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.path)
+
+		w = sessionRequest(router, tc.method, tc.path+"?secret=wrong", tc.body, "")
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.path)
+
+		w = sessionRequest(router, tc.method, tc.path+"?secret=secret", tc.body, "")
+		assert.Less(t, w.Code, 300, tc.path)
+
+		w = sessionRequest(router, tc.method, tc.path, tc.body, "sid")
+		assert.Less(t, w.Code, 300, tc.path)
+	}
+
+	assert.Len(t, s.UpdateHookCalls(), 10)
+}
+
+func TestAPIGetHookPublicSecret(t *testing.T) {
+	s := &storeMock{
+		SessionFunc: testSession,
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name, Secret: "secret", Owner: "team"}, nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", APIGetHook(s))
+
+	w := sessionRequest(router, http.MethodGet, "/foo", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"secret":"secret"`)
+
+	w = sessionRequest(router, http.MethodGet, "/foo", "", "sid")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"secret":"secret"`)
+}
+
+// testSession returns a session of "team" user for "sid" id
+func testSession(id string) (*models.Session, error) {
+	if id != "sid" {
+		return nil, nil
+	}
+
+	return &models.Session{ID: id, User: "team", Expires: time.Now().Add(time.Hour)}, nil
+}
+
+// sessionRequest serves a request with the session cookie, empty session is anonymous
+func sessionRequest(h http.Handler, method string, address string, body string, session string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, address, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if session != "" {
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: session})
+	}
+
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	return resp
+}
@@ -1,8 +1,9 @@
 package handlers
 
-//go:generate moq -skip-ensure -out mock.go . store tpl
+//go:generate moq -skip-ensure -out mock.go . store tpl publisher subscriber replayer notifier
 
 import (
+	"context"
 	"io"
 
 	"github.com/dotzero/hooks/app/models"
@@ -11,13 +12,42 @@ import (
 type store interface {
 	Hook(name string) (*models.Hook, error)
 	PutHook(hook *models.Hook) error
+	UpdateHook(hook *models.Hook) error
+	RenameHook(name string, newName string) error
+	DeleteHook(name string) error
 	RecentHooks(max int) ([]*models.Hook, error)
+	UserHooks(user string) ([]*models.Hook, error)
 	Requests(hook string) ([]*models.Request, error)
+	RequestsPage(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error)
+	NextRequest(hook string, after string, match func(req *models.Request) bool) (*models.Request, error)
+	Request(hook string, name string) (*models.Request, error)
 	PutRequest(hook string, req *models.Request) error
+	UpdateRequest(hook string, req *models.Request) error
+	User(name string) (*models.User, error)
+	PutUser(user *models.User) error
+	Session(id string) (*models.Session, error)
+	PutSession(session *models.Session) error
+	DeleteSession(id string) error
 	Counters() (map[string]int, error)
-	Count(name []byte) (int, error)
 }
 
 type tpl interface {
 	Execute(wr io.Writer, data interface{}) error
 }
+
+type publisher interface {
+	Publish(hook string, req *models.Request)
+}
+
+type subscriber interface {
+	Subscribe(hook string) (<-chan *models.Request, func())
+}
+
+type replayer interface {
+	Replay(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error)
+}
+
+type notifier interface {
+	Notify(hook *models.Hook, req *models.Request)
+	EmailEnabled() bool
+}
@@ -4,6 +4,7 @@
 package handlers
 
 import (
+	"context"
 	"github.com/dotzero/hooks/app/models"
 	"io"
 	"sync"
@@ -15,27 +16,63 @@ import (
 //
 // 		// make and configure a mocked store
 // 		mockedstore := &storeMock{
-// 			CountFunc: func(name []byte) (int, error) {
-// 				panic("mock out the Count method")
-// 			},
 // 			CountersFunc: func() (map[string]int, error) {
 // 				panic("mock out the Counters method")
 // 			},
+// 			DeleteHookFunc: func(name string) error {
+// 				panic("mock out the DeleteHook method")
+// 			},
+// 			DeleteSessionFunc: func(id string) error {
+// 				panic("mock out the DeleteSession method")
+// 			},
 // 			HookFunc: func(name string) (*models.Hook, error) {
 // 				panic("mock out the Hook method")
 // 			},
+// 			NextRequestFunc: func(hook string, after string, match func(req *models.Request) bool) (*models.Request, error) {
+// 				panic("mock out the NextRequest method")
+// 			},
 // 			PutHookFunc: func(hook *models.Hook) error {
 // 				panic("mock out the PutHook method")
 // 			},
 // 			PutRequestFunc: func(hook string, req *models.Request) error {
 // 				panic("mock out the PutRequest method")
 // 			},
+// 			PutSessionFunc: func(session *models.Session) error {
+// 				panic("mock out the PutSession method")
+// 			},
+// 			PutUserFunc: func(user *models.User) error {
+// 				panic("mock out the PutUser method")
+// 			},
 // 			RecentHooksFunc: func(max int) ([]*models.Hook, error) {
 // 				panic("mock out the RecentHooks method")
 // 			},
+// 			RenameHookFunc: func(name string, newName string) error {
+// 				panic("mock out the RenameHook method")
+// 			},
+// 			RequestFunc: func(hook string, name string) (*models.Request, error) {
+// 				panic("mock out the Request method")
+// 			},
 // 			RequestsFunc: func(hook string) ([]*models.Request, error) {
 // 				panic("mock out the Requests method")
 // 			},
+// 			RequestsPageFunc: func(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+// 				panic("mock out the RequestsPage method")
+// 			},
+// 			SessionFunc: func(id string) (*models.Session, error) {
+// 				panic("mock out the Session method")
+// 			},
+// 			UpdateHookFunc: func(hook *models.Hook) error {
+// 				panic("mock out the UpdateHook method")
+// 			},
+// 			UpdateRequestFunc: func(hook string, req *models.Request) error {
+// 				panic("mock out the UpdateRequest method")
+// 			},
+// 			UserFunc: func(name string) (*models.User, error) {
+// 				panic("mock out the User method")
+// 			},
+// 			UserHooksFunc: func(user string) ([]*models.Hook, error) {
+// 				panic("mock out the UserHooks method")
+// 			},
 // 		}
 //
 // 		// use mockedstore in code that requires store
@@ -43,42 +80,92 @@ import (
 //
 // 	}
 type storeMock struct {
-	// CountFunc mocks the Count method.
-	CountFunc func(name []byte) (int, error)
-
 	// CountersFunc mocks the Counters method.
 	CountersFunc func() (map[string]int, error)
 
+	// DeleteHookFunc mocks the DeleteHook method.
+	DeleteHookFunc func(name string) error
+
+	// DeleteSessionFunc mocks the DeleteSession method.
+	DeleteSessionFunc func(id string) error
+
 	// HookFunc mocks the Hook method.
 	HookFunc func(name string) (*models.Hook, error)
 
+	// NextRequestFunc mocks the NextRequest method.
+	NextRequestFunc func(hook string, after string, match func(req *models.Request) bool) (*models.Request, error)
+
 	// PutHookFunc mocks the PutHook method.
 	PutHookFunc func(hook *models.Hook) error
 
 	// PutRequestFunc mocks the PutRequest method.
 	PutRequestFunc func(hook string, req *models.Request) error
 
+	// PutSessionFunc mocks the PutSession method.
+	PutSessionFunc func(session *models.Session) error
+
+	// PutUserFunc mocks the PutUser method.
+	PutUserFunc func(user *models.User) error
+
 	// RecentHooksFunc mocks the RecentHooks method.
 	RecentHooksFunc func(max int) ([]*models.Hook, error)
 
+	// RenameHookFunc mocks the RenameHook method.
+	RenameHookFunc func(name string, newName string) error
+
+	// RequestFunc mocks the Request method.
+	RequestFunc func(hook string, name string) (*models.Request, error)
+
 	// RequestsFunc mocks the Requests method.
 	RequestsFunc func(hook string) ([]*models.Request, error)
 
+	// RequestsPageFunc mocks the RequestsPage method.
+	RequestsPageFunc func(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error)
+
+	// SessionFunc mocks the Session method.
+	SessionFunc func(id string) (*models.Session, error)
+
+	// UpdateHookFunc mocks the UpdateHook method.
+	UpdateHookFunc func(hook *models.Hook) error
+
+	// UpdateRequestFunc mocks the UpdateRequest method.
+	UpdateRequestFunc func(hook string, req *models.Request) error
+
+	// UserFunc mocks the User method.
+	UserFunc func(name string) (*models.User, error)
+
+	// UserHooksFunc mocks the UserHooks method.
+	UserHooksFunc func(user string) ([]*models.Hook, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
-		// Count holds details about calls to the Count method.
-		Count []struct {
-			// Name is the name argument value.
-			Name []byte
-		}
 		// Counters holds details about calls to the Counters method.
 		Counters []struct {
 		}
+		// DeleteHook holds details about calls to the DeleteHook method.
+		DeleteHook []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// DeleteSession holds details about calls to the DeleteSession method.
+		DeleteSession []struct {
+			// Id is the id argument value.
+			Id string
+		}
 		// Hook holds details about calls to the Hook method.
 		Hook []struct {
 			// Name is the name argument value.
 			Name string
 		}
+		// NextRequest holds details about calls to the NextRequest method.
+		NextRequest []struct {
+			// Hook is the hook argument value.
+			Hook string
+			// After is the after argument value.
+			After string
+			// Match is the match argument value.
+			Match func(req *models.Request) bool
+		}
 		// PutHook holds details about calls to the PutHook method.
 		PutHook []struct {
 			// Hook is the hook argument value.
@@ -91,55 +178,98 @@ type storeMock struct {
 			// Req is the req argument value.
 			Req *models.Request
 		}
+		// PutSession holds details about calls to the PutSession method.
+		PutSession []struct {
+			// Session is the session argument value.
+			Session *models.Session
+		}
+		// PutUser holds details about calls to the PutUser method.
+		PutUser []struct {
+			// User is the user argument value.
+			User *models.User
+		}
 		// RecentHooks holds details about calls to the RecentHooks method.
 		RecentHooks []struct {
 			// Max is the max argument value.
 			Max int
 		}
+		// RenameHook holds details about calls to the RenameHook method.
+		RenameHook []struct {
+			// Name is the name argument value.
+			Name string
+			// NewName is the newName argument value.
+			NewName string
+		}
+		// Request holds details about calls to the Request method.
+		Request []struct {
+			// Hook is the hook argument value.
+			Hook string
+			// Name is the name argument value.
+			Name string
+		}
 		// Requests holds details about calls to the Requests method.
 		Requests []struct {
 			// Hook is the hook argument value.
 			Hook string
 		}
+		// RequestsPage holds details about calls to the RequestsPage method.
+		RequestsPage []struct {
+			// Hook is the hook argument value.
+			Hook string
+			// Before is the before argument value.
+			Before string
+			// Limit is the limit argument value.
+			Limit int
+			// Match is the match argument value.
+			Match func(req *models.Request) bool
+		}
+		// Session holds details about calls to the Session method.
+		Session []struct {
+			// Id is the id argument value.
+			Id string
+		}
+		// UpdateHook holds details about calls to the UpdateHook method.
+		UpdateHook []struct {
+			// Hook is the hook argument value.
+			Hook *models.Hook
+		}
+		// UpdateRequest holds details about calls to the UpdateRequest method.
+		UpdateRequest []struct {
+			// Hook is the hook argument value.
+			Hook string
+			// Req is the req argument value.
+			Req *models.Request
+		}
+		// User holds details about calls to the User method.
+		User []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// UserHooks holds details about calls to the UserHooks method.
+		UserHooks []struct {
+			// User is the user argument value.
+			User string
+		}
 	}
-	lockCount       sync.RWMutex
-	lockCounters    sync.RWMutex
-	lockHook        sync.RWMutex
-	lockPutHook     sync.RWMutex
-	lockPutRequest  sync.RWMutex
-	lockRecentHooks sync.RWMutex
-	lockRequests    sync.RWMutex
-}
-
-// Count calls CountFunc.
-func (mock *storeMock) Count(name []byte) (int, error) {
-	if mock.CountFunc == nil {
-		panic("storeMock.CountFunc: method is nil but store.Count was just called")
-	}
-	callInfo := struct {
-		Name []byte
-	}{
-		Name: name,
-	}
-	mock.lockCount.Lock()
-	mock.calls.Count = append(mock.calls.Count, callInfo)
-	mock.lockCount.Unlock()
-	return mock.CountFunc(name)
-}
-
-// CountCalls gets all the calls that were made to Count.
-// Check the length with:
-//     len(mockedstore.CountCalls())
-func (mock *storeMock) CountCalls() []struct {
-	Name []byte
-} {
-	var calls []struct {
-		Name []byte
-	}
-	mock.lockCount.RLock()
-	calls = mock.calls.Count
-	mock.lockCount.RUnlock()
-	return calls
+	lockCounters      sync.RWMutex
+	lockDeleteHook    sync.RWMutex
+	lockDeleteSession sync.RWMutex
+	lockHook          sync.RWMutex
+	lockNextRequest   sync.RWMutex
+	lockPutHook       sync.RWMutex
+	lockPutRequest    sync.RWMutex
+	lockPutSession    sync.RWMutex
+	lockPutUser       sync.RWMutex
+	lockRecentHooks   sync.RWMutex
+	lockRenameHook    sync.RWMutex
+	lockRequest       sync.RWMutex
+	lockRequests      sync.RWMutex
+	lockRequestsPage  sync.RWMutex
+	lockSession       sync.RWMutex
+	lockUpdateHook    sync.RWMutex
+	lockUpdateRequest sync.RWMutex
+	lockUser          sync.RWMutex
+	lockUserHooks     sync.RWMutex
 }
 
 // Counters calls CountersFunc.
@@ -168,6 +298,68 @@ func (mock *storeMock) CountersCalls() []struct {
 	return calls
 }
 
+// DeleteHook calls DeleteHookFunc.
+func (mock *storeMock) DeleteHook(name string) error {
+	if mock.DeleteHookFunc == nil {
+		panic("storeMock.DeleteHookFunc: method is nil but store.DeleteHook was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockDeleteHook.Lock()
+	mock.calls.DeleteHook = append(mock.calls.DeleteHook, callInfo)
+	mock.lockDeleteHook.Unlock()
+	return mock.DeleteHookFunc(name)
+}
+
+// DeleteHookCalls gets all the calls that were made to DeleteHook.
+// Check the length with:
+//     len(mockedstore.DeleteHookCalls())
+func (mock *storeMock) DeleteHookCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockDeleteHook.RLock()
+	calls = mock.calls.DeleteHook
+	mock.lockDeleteHook.RUnlock()
+	return calls
+}
+
+// DeleteSession calls DeleteSessionFunc.
+func (mock *storeMock) DeleteSession(id string) error {
+	if mock.DeleteSessionFunc == nil {
+		panic("storeMock.DeleteSessionFunc: method is nil but store.DeleteSession was just called")
+	}
+	callInfo := struct {
+		Id string
+	}{
+		Id: id,
+	}
+	mock.lockDeleteSession.Lock()
+	mock.calls.DeleteSession = append(mock.calls.DeleteSession, callInfo)
+	mock.lockDeleteSession.Unlock()
+	return mock.DeleteSessionFunc(id)
+}
+
+// DeleteSessionCalls gets all the calls that were made to DeleteSession.
+// Check the length with:
+//     len(mockedstore.DeleteSessionCalls())
+func (mock *storeMock) DeleteSessionCalls() []struct {
+	Id string
+} {
+	var calls []struct {
+		Id string
+	}
+	mock.lockDeleteSession.RLock()
+	calls = mock.calls.DeleteSession
+	mock.lockDeleteSession.RUnlock()
+	return calls
+}
+
 // Hook calls HookFunc.
 func (mock *storeMock) Hook(name string) (*models.Hook, error) {
 	if mock.HookFunc == nil {
@@ -199,6 +391,45 @@ func (mock *storeMock) HookCalls() []struct {
 	return calls
 }
 
+// NextRequest calls NextRequestFunc.
+func (mock *storeMock) NextRequest(hook string, after string, match func(req *models.Request) bool) (*models.Request, error) {
+	if mock.NextRequestFunc == nil {
+		panic("storeMock.NextRequestFunc: method is nil but store.NextRequest was just called")
+	}
+	callInfo := struct {
+		Hook  string
+		After string
+		Match func(req *models.Request) bool
+	}{
+		Hook:  hook,
+		After: after,
+		Match: match,
+	}
+	mock.lockNextRequest.Lock()
+	mock.calls.NextRequest = append(mock.calls.NextRequest, callInfo)
+	mock.lockNextRequest.Unlock()
+	return mock.NextRequestFunc(hook, after, match)
+}
+
+// NextRequestCalls gets all the calls that were made to NextRequest.
+// Check the length with:
+//     len(mockedstore.NextRequestCalls())
+func (mock *storeMock) NextRequestCalls() []struct {
+	Hook  string
+	After string
+	Match func(req *models.Request) bool
+} {
+	var calls []struct {
+		Hook  string
+		After string
+		Match func(req *models.Request) bool
+	}
+	mock.lockNextRequest.RLock()
+	calls = mock.calls.NextRequest
+	mock.lockNextRequest.RUnlock()
+	return calls
+}
+
 // PutHook calls PutHookFunc.
 func (mock *storeMock) PutHook(hook *models.Hook) error {
 	if mock.PutHookFunc == nil {
@@ -265,6 +496,68 @@ func (mock *storeMock) PutRequestCalls() []struct {
 	return calls
 }
 
+// PutSession calls PutSessionFunc.
+func (mock *storeMock) PutSession(session *models.Session) error {
+	if mock.PutSessionFunc == nil {
+		panic("storeMock.PutSessionFunc: method is nil but store.PutSession was just called")
+	}
+	callInfo := struct {
+		Session *models.Session
+	}{
+		Session: session,
+	}
+	mock.lockPutSession.Lock()
+	mock.calls.PutSession = append(mock.calls.PutSession, callInfo)
+	mock.lockPutSession.Unlock()
+	return mock.PutSessionFunc(session)
+}
+
+// PutSessionCalls gets all the calls that were made to PutSession.
+// Check the length with:
+//     len(mockedstore.PutSessionCalls())
+func (mock *storeMock) PutSessionCalls() []struct {
+	Session *models.Session
+} {
+	var calls []struct {
+		Session *models.Session
+	}
+	mock.lockPutSession.RLock()
+	calls = mock.calls.PutSession
+	mock.lockPutSession.RUnlock()
+	return calls
+}
+
+// PutUser calls PutUserFunc.
+func (mock *storeMock) PutUser(user *models.User) error {
+	if mock.PutUserFunc == nil {
+		panic("storeMock.PutUserFunc: method is nil but store.PutUser was just called")
+	}
+	callInfo := struct {
+		User *models.User
+	}{
+		User: user,
+	}
+	mock.lockPutUser.Lock()
+	mock.calls.PutUser = append(mock.calls.PutUser, callInfo)
+	mock.lockPutUser.Unlock()
+	return mock.PutUserFunc(user)
+}
+
+// PutUserCalls gets all the calls that were made to PutUser.
+// Check the length with:
+//     len(mockedstore.PutUserCalls())
+func (mock *storeMock) PutUserCalls() []struct {
+	User *models.User
+} {
+	var calls []struct {
+		User *models.User
+	}
+	mock.lockPutUser.RLock()
+	calls = mock.calls.PutUser
+	mock.lockPutUser.RUnlock()
+	return calls
+}
+
 // RecentHooks calls RecentHooksFunc.
 func (mock *storeMock) RecentHooks(max int) ([]*models.Hook, error) {
 	if mock.RecentHooksFunc == nil {
@@ -296,6 +589,76 @@ func (mock *storeMock) RecentHooksCalls() []struct {
 	return calls
 }
 
+// RenameHook calls RenameHookFunc.
+func (mock *storeMock) RenameHook(name string, newName string) error {
+	if mock.RenameHookFunc == nil {
+		panic("storeMock.RenameHookFunc: method is nil but store.RenameHook was just called")
+	}
+	callInfo := struct {
+		Name    string
+		NewName string
+	}{
+		Name:    name,
+		NewName: newName,
+	}
+	mock.lockRenameHook.Lock()
+	mock.calls.RenameHook = append(mock.calls.RenameHook, callInfo)
+	mock.lockRenameHook.Unlock()
+	return mock.RenameHookFunc(name, newName)
+}
+
+// RenameHookCalls gets all the calls that were made to RenameHook.
+// Check the length with:
+//     len(mockedstore.RenameHookCalls())
+func (mock *storeMock) RenameHookCalls() []struct {
+	Name    string
+	NewName string
+} {
+	var calls []struct {
+		Name    string
+		NewName string
+	}
+	mock.lockRenameHook.RLock()
+	calls = mock.calls.RenameHook
+	mock.lockRenameHook.RUnlock()
+	return calls
+}
+
+// Request calls RequestFunc.
+func (mock *storeMock) Request(hook string, name string) (*models.Request, error) {
+	if mock.RequestFunc == nil {
+		panic("storeMock.RequestFunc: method is nil but store.Request was just called")
+	}
+	callInfo := struct {
+		Hook string
+		Name string
+	}{
+		Hook: hook,
+		Name: name,
+	}
+	mock.lockRequest.Lock()
+	mock.calls.Request = append(mock.calls.Request, callInfo)
+	mock.lockRequest.Unlock()
+	return mock.RequestFunc(hook, name)
+}
+
+// RequestCalls gets all the calls that were made to Request.
+// Check the length with:
+//     len(mockedstore.RequestCalls())
+func (mock *storeMock) RequestCalls() []struct {
+	Hook string
+	Name string
+} {
+	var calls []struct {
+		Hook string
+		Name string
+	}
+	mock.lockRequest.RLock()
+	calls = mock.calls.Request
+	mock.lockRequest.RUnlock()
+	return calls
+}
+
 // Requests calls RequestsFunc.
 func (mock *storeMock) Requests(hook string) ([]*models.Request, error) {
 	if mock.RequestsFunc == nil {
@@ -327,6 +690,208 @@ func (mock *storeMock) RequestsCalls() []struct {
 	return calls
 }
 
+// RequestsPage calls RequestsPageFunc.
+func (mock *storeMock) RequestsPage(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+	if mock.RequestsPageFunc == nil {
+		panic("storeMock.RequestsPageFunc: method is nil but store.RequestsPage was just called")
+	}
+	callInfo := struct {
+		Hook   string
+		Before string
+		Limit  int
+		Match  func(req *models.Request) bool
+	}{
+		Hook:   hook,
+		Before: before,
+		Limit:  limit,
+		Match:  match,
+	}
+	mock.lockRequestsPage.Lock()
+	mock.calls.RequestsPage = append(mock.calls.RequestsPage, callInfo)
+	mock.lockRequestsPage.Unlock()
+	return mock.RequestsPageFunc(hook, before, limit, match)
+}
+
+// RequestsPageCalls gets all the calls that were made to RequestsPage.
+// Check the length with:
+//     len(mockedstore.RequestsPageCalls())
+func (mock *storeMock) RequestsPageCalls() []struct {
+	Hook   string
+	Before string
+	Limit  int
+	Match  func(req *models.Request) bool
+} {
+	var calls []struct {
+		Hook   string
+		Before string
+		Limit  int
+		Match  func(req *models.Request) bool
+	}
+	mock.lockRequestsPage.RLock()
+	calls = mock.calls.RequestsPage
+	mock.lockRequestsPage.RUnlock()
+	return calls
+}
+
+// Session calls SessionFunc.
+func (mock *storeMock) Session(id string) (*models.Session, error) {
+	if mock.SessionFunc == nil {
+		panic("storeMock.SessionFunc: method is nil but store.Session was just called")
+	}
+	callInfo := struct {
+		Id string
+	}{
+		Id: id,
+	}
+	mock.lockSession.Lock()
+	mock.calls.Session = append(mock.calls.Session, callInfo)
+	mock.lockSession.Unlock()
+	return mock.SessionFunc(id)
+}
+
+// SessionCalls gets all the calls that were made to Session.
+// Check the length with:
+//     len(mockedstore.SessionCalls())
+func (mock *storeMock) SessionCalls() []struct {
+	Id string
+} {
+	var calls []struct {
+		Id string
+	}
+	mock.lockSession.RLock()
+	calls = mock.calls.Session
+	mock.lockSession.RUnlock()
+	return calls
+}
+
+// UpdateHook calls UpdateHookFunc.
+func (mock *storeMock) UpdateHook(hook *models.Hook) error {
+	if mock.UpdateHookFunc == nil {
+		panic("storeMock.UpdateHookFunc: method is nil but store.UpdateHook was just called")
+	}
+	callInfo := struct {
+		Hook *models.Hook
+	}{
+		Hook: hook,
+	}
+	mock.lockUpdateHook.Lock()
+	mock.calls.UpdateHook = append(mock.calls.UpdateHook, callInfo)
+	mock.lockUpdateHook.Unlock()
+	return mock.UpdateHookFunc(hook)
+}
+
+// UpdateHookCalls gets all the calls that were made to UpdateHook.
+// Check the length with:
+//     len(mockedstore.UpdateHookCalls())
+func (mock *storeMock) UpdateHookCalls() []struct {
+	Hook *models.Hook
+} {
+	var calls []struct {
+		Hook *models.Hook
+	}
+	mock.lockUpdateHook.RLock()
+	calls = mock.calls.UpdateHook
+	mock.lockUpdateHook.RUnlock()
+	return calls
+}
+
+// UpdateRequest calls UpdateRequestFunc.
+func (mock *storeMock) UpdateRequest(hook string, req *models.Request) error {
+	if mock.UpdateRequestFunc == nil {
+		panic("storeMock.UpdateRequestFunc: method is nil but store.UpdateRequest was just called")
+	}
+	callInfo := struct {
+		Hook string
+		Req  *models.Request
+	}{
+		Hook: hook,
+		Req:  req,
+	}
+	mock.lockUpdateRequest.Lock()
+	mock.calls.UpdateRequest = append(mock.calls.UpdateRequest, callInfo)
+	mock.lockUpdateRequest.Unlock()
+	return mock.UpdateRequestFunc(hook, req)
+}
+
+// UpdateRequestCalls gets all the calls that were made to UpdateRequest.
+// Check the length with:
+//     len(mockedstore.UpdateRequestCalls())
+func (mock *storeMock) UpdateRequestCalls() []struct {
+	Hook string
+	Req  *models.Request
+} {
+	var calls []struct {
+		Hook string
+		Req  *models.Request
+	}
+	mock.lockUpdateRequest.RLock()
+	calls = mock.calls.UpdateRequest
+	mock.lockUpdateRequest.RUnlock()
+	return calls
+}
+
+// User calls UserFunc.
+func (mock *storeMock) User(name string) (*models.User, error) {
+	if mock.UserFunc == nil {
+		panic("storeMock.UserFunc: method is nil but store.User was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockUser.Lock()
+	mock.calls.User = append(mock.calls.User, callInfo)
+	mock.lockUser.Unlock()
+	return mock.UserFunc(name)
+}
+
+// UserCalls gets all the calls that were made to User.
+// Check the length with:
+//     len(mockedstore.UserCalls())
+func (mock *storeMock) UserCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockUser.RLock()
+	calls = mock.calls.User
+	mock.lockUser.RUnlock()
+	return calls
+}
+
+// UserHooks calls UserHooksFunc.
+func (mock *storeMock) UserHooks(user string) ([]*models.Hook, error) {
+	if mock.UserHooksFunc == nil {
+		panic("storeMock.UserHooksFunc: method is nil but store.UserHooks was just called")
+	}
+	callInfo := struct {
+		User string
+	}{
+		User: user,
+	}
+	mock.lockUserHooks.Lock()
+	mock.calls.UserHooks = append(mock.calls.UserHooks, callInfo)
+	mock.lockUserHooks.Unlock()
+	return mock.UserHooksFunc(user)
+}
+
+// UserHooksCalls gets all the calls that were made to UserHooks.
+// Check the length with:
+//     len(mockedstore.UserHooksCalls())
+func (mock *storeMock) UserHooksCalls() []struct {
+	User string
+} {
+	var calls []struct {
+		User string
+	}
+	mock.lockUserHooks.RLock()
+	calls = mock.calls.UserHooks
+	mock.lockUserHooks.RUnlock()
+	return calls
+}
+
 // tplMock is a mock implementation of tpl.
 //
 // 	func TestSomethingThatUsestpl(t *testing.T) {
@@ -393,3 +958,313 @@ func (mock *tplMock) ExecuteCalls() []struct {
 	mock.lockExecute.RUnlock()
 	return calls
 }
+
+// publisherMock is a mock implementation of publisher.
+//
+// 	func TestSomethingThatUsespublisher(t *testing.T) {
+//
+// 		// make and configure a mocked publisher
+// 		mockedpublisher := &publisherMock{
+// 			PublishFunc: func(hook string, req *models.Request) {
+// 				panic("mock out the Publish method")
+// 			},
+// 		}
+//
+// 		// use mockedpublisher in code that requires publisher
+// 		// and then make assertions.
+//
+// 	}
+type publisherMock struct {
+	// PublishFunc mocks the Publish method.
+	PublishFunc func(hook string, req *models.Request)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Publish holds details about calls to the Publish method.
+		Publish []struct {
+			// Hook is the hook argument value.
+			Hook string
+			// Req is the req argument value.
+			Req *models.Request
+		}
+	}
+	lockPublish sync.RWMutex
+}
+
+// Publish calls PublishFunc.
+func (mock *publisherMock) Publish(hook string, req *models.Request) {
+	if mock.PublishFunc == nil {
+		panic("publisherMock.PublishFunc: method is nil but publisher.Publish was just called")
+	}
+	callInfo := struct {
+		Hook string
+		Req  *models.Request
+	}{
+		Hook: hook,
+		Req:  req,
+	}
+	mock.lockPublish.Lock()
+	mock.calls.Publish = append(mock.calls.Publish, callInfo)
+	mock.lockPublish.Unlock()
+	mock.PublishFunc(hook, req)
+}
+
+// PublishCalls gets all the calls that were made to Publish.
+// Check the length with:
+//     len(mockedpublisher.PublishCalls())
+func (mock *publisherMock) PublishCalls() []struct {
+	Hook string
+	Req  *models.Request
+} {
+	var calls []struct {
+		Hook string
+		Req  *models.Request
+	}
+	mock.lockPublish.RLock()
+	calls = mock.calls.Publish
+	mock.lockPublish.RUnlock()
+	return calls
+}
+
+// subscriberMock is a mock implementation of subscriber.
+//
+// 	func TestSomethingThatUsessubscriber(t *testing.T) {
+//
+// 		// make and configure a mocked subscriber
+// 		mockedsubscriber := &subscriberMock{
+// 			SubscribeFunc: func(hook string) (<-chan *models.Request, func()) {
+// 				panic("mock out the Subscribe method")
+// 			},
+// 		}
+//
+// 		// use mockedsubscriber in code that requires subscriber
+// 		// and then make assertions.
+//
+// 	}
+type subscriberMock struct {
+	// SubscribeFunc mocks the Subscribe method.
+	SubscribeFunc func(hook string) (<-chan *models.Request, func())
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Subscribe holds details about calls to the Subscribe method.
+		Subscribe []struct {
+			// Hook is the hook argument value.
+			Hook string
+		}
+	}
+	lockSubscribe sync.RWMutex
+}
+
+// Subscribe calls SubscribeFunc.
+func (mock *subscriberMock) Subscribe(hook string) (<-chan *models.Request, func()) {
+	if mock.SubscribeFunc == nil {
+		panic("subscriberMock.SubscribeFunc: method is nil but subscriber.Subscribe was just called")
+	}
+	callInfo := struct {
+		Hook string
+	}{
+		Hook: hook,
+	}
+	mock.lockSubscribe.Lock()
+	mock.calls.Subscribe = append(mock.calls.Subscribe, callInfo)
+	mock.lockSubscribe.Unlock()
+	return mock.SubscribeFunc(hook)
+}
+
+// SubscribeCalls gets all the calls that were made to Subscribe.
+// Check the length with:
+//     len(mockedsubscriber.SubscribeCalls())
+func (mock *subscriberMock) SubscribeCalls() []struct {
+	Hook string
+} {
+	var calls []struct {
+		Hook string
+	}
+	mock.lockSubscribe.RLock()
+	calls = mock.calls.Subscribe
+	mock.lockSubscribe.RUnlock()
+	return calls
+}
+
+// replayerMock is a mock implementation of replayer.
+//
+// 	func TestSomethingThatUsesreplayer(t *testing.T) {
+//
+// 		// make and configure a mocked replayer
+// 		mockedreplayer := &replayerMock{
+// 			ReplayFunc: func(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error) {
+// 				panic("mock out the Replay method")
+// 			},
+// 		}
+//
+// 		// use mockedreplayer in code that requires replayer
+// 		// and then make assertions.
+//
+// 	}
+type replayerMock struct {
+	// ReplayFunc mocks the Replay method.
+	ReplayFunc func(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Replay holds details about calls to the Replay method.
+		Replay []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Target is the target argument value.
+			Target string
+			// Hook is the hook argument value.
+			Hook string
+			// Req is the req argument value.
+			Req *models.Request
+		}
+	}
+	lockReplay sync.RWMutex
+}
+
+// Replay calls ReplayFunc.
+func (mock *replayerMock) Replay(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error) {
+	if mock.ReplayFunc == nil {
+		panic("replayerMock.ReplayFunc: method is nil but replayer.Replay was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Target string
+		Hook   string
+		Req    *models.Request
+	}{
+		Ctx:    ctx,
+		Target: target,
+		Hook:   hook,
+		Req:    req,
+	}
+	mock.lockReplay.Lock()
+	mock.calls.Replay = append(mock.calls.Replay, callInfo)
+	mock.lockReplay.Unlock()
+	return mock.ReplayFunc(ctx, target, hook, req)
+}
+
+// ReplayCalls gets all the calls that were made to Replay.
+// Check the length with:
+//     len(mockedreplayer.ReplayCalls())
+func (mock *replayerMock) ReplayCalls() []struct {
+	Ctx    context.Context
+	Target string
+	Hook   string
+	Req    *models.Request
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Target string
+		Hook   string
+		Req    *models.Request
+	}
+	mock.lockReplay.RLock()
+	calls = mock.calls.Replay
+	mock.lockReplay.RUnlock()
+	return calls
+}
+
+// notifierMock is a mock implementation of notifier.
+//
+// 	func TestSomethingThatUsesnotifier(t *testing.T) {
+//
+// 		// make and configure a mocked notifier
+// 		mockednotifier := &notifierMock{
+// 			EmailEnabledFunc: func() bool {
+// 				panic("mock out the EmailEnabled method")
+// 			},
+// 			NotifyFunc: func(hook *models.Hook, req *models.Request) {
+// 				panic("mock out the Notify method")
+// 			},
+// 		}
+//
+// 		// use mockednotifier in code that requires notifier
+// 		// and then make assertions.
+//
+// 	}
+type notifierMock struct {
+	// EmailEnabledFunc mocks the EmailEnabled method.
+	EmailEnabledFunc func() bool
+
+	// NotifyFunc mocks the Notify method.
+	NotifyFunc func(hook *models.Hook, req *models.Request)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// EmailEnabled holds details about calls to the EmailEnabled method.
+		EmailEnabled []struct {
+		}
+		// Notify holds details about calls to the Notify method.
+		Notify []struct {
+			// Hook is the hook argument value.
+			Hook *models.Hook
+			// Req is the req argument value.
+			Req *models.Request
+		}
+	}
+	lockEmailEnabled sync.RWMutex
+	lockNotify       sync.RWMutex
+}
+
+// EmailEnabled calls EmailEnabledFunc.
+func (mock *notifierMock) EmailEnabled() bool {
+	if mock.EmailEnabledFunc == nil {
+		panic("notifierMock.EmailEnabledFunc: method is nil but notifier.EmailEnabled was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockEmailEnabled.Lock()
+	mock.calls.EmailEnabled = append(mock.calls.EmailEnabled, callInfo)
+	mock.lockEmailEnabled.Unlock()
+	return mock.EmailEnabledFunc()
+}
+
+// EmailEnabledCalls gets all the calls that were made to EmailEnabled.
+// Check the length with:
+//     len(mockednotifier.EmailEnabledCalls())
+func (mock *notifierMock) EmailEnabledCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockEmailEnabled.RLock()
+	calls = mock.calls.EmailEnabled
+	mock.lockEmailEnabled.RUnlock()
+	return calls
+}
+
+// Notify calls NotifyFunc.
+func (mock *notifierMock) Notify(hook *models.Hook, req *models.Request) {
+	if mock.NotifyFunc == nil {
+		panic("notifierMock.NotifyFunc: method is nil but notifier.Notify was just called")
+	}
+	callInfo := struct {
+		Hook *models.Hook
+		Req  *models.Request
+	}{
+		Hook: hook,
+		Req:  req,
+	}
+	mock.lockNotify.Lock()
+	mock.calls.Notify = append(mock.calls.Notify, callInfo)
+	mock.lockNotify.Unlock()
+	mock.NotifyFunc(hook, req)
+}
+
+// NotifyCalls gets all the calls that were made to Notify.
+// Check the length with:
+//     len(mockednotifier.NotifyCalls())
+func (mock *notifierMock) NotifyCalls() []struct {
+	Hook *models.Hook
+	Req  *models.Request
+} {
+	var calls []struct {
+		Hook *models.Hook
+		Req  *models.Request
+	}
+	mock.lockNotify.RLock()
+	calls = mock.calls.Notify
+	mock.lockNotify.RUnlock()
+	return calls
+}
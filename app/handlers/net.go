@@ -2,19 +2,44 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/render"
 )
 
-var errNotFound = errors.New("Hook is not found")
+var (
+	errNotFound        = errors.New("Hook is not found")
+	errRequestNotFound = errors.New("Request is not found")
+	errFileNotFound    = errors.New("File is not found")
+	errBadRequest      = errors.New("Bad request")
+	errUnauthorized    = errors.New("Unauthorized")
+	errForbidden       = errors.New("Forbidden")
+	errConflict        = errors.New("Conflict")
+
+	errBodyTooLarge     = errors.New("Request body exceeds max body size")
+	errUpstreamTooLarge = errors.New("Upstream response exceeds max body size")
+	errLoopDetected     = errors.New("Request is already forwarded by hooks")
+)
 
 func renderError(w http.ResponseWriter, r *http.Request, err error) {
-	switch err {
-	case errNotFound:
+	switch {
+	case errors.Is(err, errNotFound), errors.Is(err, errRequestNotFound), errors.Is(err, errFileNotFound):
 		render.Status(r, http.StatusNotFound)
+	case errors.Is(err, errBadRequest):
+		render.Status(r, http.StatusBadRequest)
+	case errors.Is(err, errUnauthorized):
+		render.Status(r, http.StatusUnauthorized)
+	case errors.Is(err, errForbidden):
+		render.Status(r, http.StatusForbidden)
+	case errors.Is(err, errConflict):
+		render.Status(r, http.StatusConflict)
+	case errors.Is(err, errLoopDetected):
+		render.Status(r, http.StatusLoopDetected)
 	default:
 		render.Status(r, http.StatusInternalServerError)
 	}
@@ -35,3 +60,45 @@ func parseBool(form url.Values, key string) bool {
 
 	return result
 }
+
+func parseInt(form url.Values, key string, def int) (int, error) {
+	v := form.Get(key)
+	if len(v) == 0 {
+		return def, nil
+	}
+
+	result, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer", errBadRequest, key)
+	}
+
+	return result, nil
+}
+
+// parseHeaderLines parses headers in "Name: value" form, one per line
+func parseHeaderLines(text string) (map[string]string, error) {
+	headers := make(map[string]string)
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i < 1 {
+			return nil, fmt.Errorf("%w: malformed header %q", errBadRequest, line)
+		}
+
+		name := http.CanonicalHeaderKey(strings.TrimSpace(line[:i]))
+		headers[name] = strings.TrimSpace(line[i+1:])
+	}
+
+	return headers, nil
+}
+
+// readCloser reads from the reader and closes the closer, e.g. the original request body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotzero/hooks/app/jsonpath"
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/storage"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+	defaultWait  = 30 * time.Second
+	maxWait      = 5 * time.Minute
+)
+
+// filterParams are parameters of requests listing filters
+var filterParams = []string{
+	"method", "path", "header", "param", "content_type", "ip", "since", "until", "q", "jsonpath", "jsonvalue",
+}
+
+// requestsQuery is a filter and a page of hook requests listing
+type requestsQuery struct {
+	Limit       int
+	Before      string // cursor of the page to start after, newest first
+	Since       time.Time
+	Until       time.Time
+	Method      string
+	Path        string     // substring of the path
+	Headers     []keyValue // header names with optional value substrings
+	Params      []keyValue // query or form keys with optional values
+	ContentType string     // substring of the content type
+	IP          *net.IPNet // source address or network
+	Text        string     // case-insensitive substring of the body
+	JSONPath    *jsonpath.Path
+	JSONValue   string // value of a scalar selected by JSONPath, any value if empty
+}
+
+// keyValue is a filter by the key with optional value
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+// requestsPage returns a page of hook requests matched by the query with the cursor of the next page
+func requestsPage(s store, hook string, q *requestsQuery) ([]*models.Request, string, error) {
+	requests, next, err := s.RequestsPage(hook, q.Before, q.Limit, q.Match)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		return nil, "", fmt.Errorf("%w: %s", errBadRequest, err)
+	}
+
+	return requests, next, err
+}
+
+func parseRequestsQuery(form url.Values) (*requestsQuery, error) {
+	limit, err := parseInt(form, "limit", defaultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 || limit > maxLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", errBadRequest, maxLimit)
+	}
+
+	q := &requestsQuery{
+		Limit:       limit,
+		Before:      form.Get("before"),
+		Method:      strings.ToUpper(form.Get("method")),
+		Path:        form.Get("path"),
+		Headers:     parseKeyValues(form["header"], ":"),
+		Params:      parseKeyValues(form["param"], "="),
+		ContentType: strings.ToLower(form.Get("content_type")),
+		Text:        strings.ToLower(form.Get("q")),
+		JSONValue:   form.Get("jsonvalue"),
+	}
+
+	if q.Since, err = parseTime(form, "since"); err != nil {
+		return nil, err
+	}
+
+	if q.Until, err = parseTime(form, "until"); err != nil {
+		return nil, err
+	}
+
+	if q.IP, err = parseIPNet(form, "ip"); err != nil {
+		return nil, err
+	}
+
+	if v := form.Get("jsonpath"); v != "" {
+		if q.JSONPath, err = jsonpath.Parse(v); err != nil {
+			return nil, fmt.Errorf("%w: %s", errBadRequest, err)
+		}
+	}
+
+	return q, nil
+}
+
+// searchFilters returns non-empty filter parameters of the form
+func searchFilters(form url.Values) url.Values {
+	filters := url.Values{}
+
+	for _, key := range filterParams {
+		for _, v := range form[key] {
+			if strings.TrimSpace(v) != "" {
+				filters.Add(key, v)
+			}
+		}
+	}
+
+	return filters
+}
+
+// Match reports whether request satisfies the query filters
+func (q *requestsQuery) Match(req *models.Request) bool {
+	if q.Method != "" && req.Method != q.Method {
+		return false
+	}
+
+	if !q.Since.IsZero() && req.Created.Before(q.Since) {
+		return false
+	}
+
+	if !q.Until.IsZero() && !req.Created.Before(q.Until) {
+		return false
+	}
+
+	if q.Path != "" && !strings.Contains(req.Path, q.Path) {
+		return false
+	}
+
+	if q.ContentType != "" && !strings.Contains(strings.ToLower(req.ContentType), q.ContentType) {
+		return false
+	}
+
+	if q.IP != nil && !q.IP.Contains(net.ParseIP(req.RemoteAddr)) {
+		return false
+	}
+
+	for _, h := range q.Headers {
+		if !matchValues(req.Headers[textproto.CanonicalMIMEHeaderKey(h.Key)], h.Value, true) {
+			return false
+		}
+	}
+
+	for _, p := range q.Params {
+		if !matchValues(req.QueryData[p.Key], p.Value, false) && !matchValues(req.FormData[p.Key], p.Value, false) {
+			return false
+		}
+	}
+
+	if q.Text != "" && !bytes.Contains(bytes.ToLower(req.Body), []byte(q.Text)) {
+		return false
+	}
+
+	if q.JSONPath != nil && !q.matchJSON(req.Body) {
+		return false
+	}
+
+	return true
+}
+
+// matchJSON reports whether the path selects a value of the JSON body
+func (q *requestsQuery) matchJSON(body []byte) bool {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return false
+	}
+
+	for _, v := range q.JSONPath.Select(doc) {
+		if q.JSONValue == "" {
+			return true
+		}
+
+		if s, ok := scalarString(v); ok && s == q.JSONValue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseKeyValues splits filters like "key" or "key<sep>value"
+func parseKeyValues(values []string, sep string) []keyValue {
+	var result []keyValue
+
+	for _, v := range values {
+		parts := strings.SplitN(v, sep, 2)
+
+		kv := keyValue{Key: strings.TrimSpace(parts[0])}
+		if len(parts) == 2 {
+			kv.Value = strings.TrimSpace(parts[1])
+		}
+
+		if kv.Key != "" {
+			result = append(result, kv)
+		}
+	}
+
+	return result
+}
+
+// matchValues reports whether the key is present with any value or the value,
+// header values match by case-insensitive substring and form values match exactly
+func matchValues(values []string, value string, substring bool) bool {
+	if values == nil {
+		return false
+	}
+
+	if value == "" {
+		return true
+	}
+
+	for _, v := range values {
+		if substring && strings.Contains(strings.ToLower(v), strings.ToLower(value)) {
+			return true
+		}
+
+		if !substring && v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scalarString returns text of the decoded JSON scalar
+func scalarString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case json.Number:
+		return s.String(), true
+	case bool:
+		return strconv.FormatBool(s), true
+	case nil:
+		return "null", true
+	}
+
+	return "", false
+}
+
+func parseIPNet(form url.Values, key string) (*net.IPNet, error) {
+	v := form.Get(key)
+	if len(v) == 0 {
+		return nil, nil
+	}
+
+	if _, ipNet, err := net.ParseCIDR(v); err == nil {
+		return ipNet, nil
+	}
+
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return nil, fmt.Errorf("%w: %s must be an ip address or a cidr network", errBadRequest, key)
+	}
+
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 8*net.IPv4len
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func parseTime(form url.Values, key string) (time.Time, error) {
+	v := form.Get(key)
+	if len(v) == 0 {
+		return time.Time{}, nil
+	}
+
+	result, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a RFC 3339 time", errBadRequest, key)
+	}
+
+	return result, nil
+}
+
+func parseDuration(form url.Values, key string, def time.Duration) (time.Duration, error) {
+	v := form.Get(key)
+	if len(v) == 0 {
+		return def, nil
+	}
+
+	result, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be a duration like 30s", errBadRequest, key)
+	}
+
+	return result, nil
+}
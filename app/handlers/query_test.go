@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+func TestParseRequestsQuery(t *testing.T) {
+	q, err := parseRequestsQuery(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, &requestsQuery{Limit: defaultLimit}, q)
+
+	q, err = parseRequestsQuery(url.Values{
+		"limit":  {"10"},
+		"before": {"abc"},
+		"method": {"post"},
+		"since":  {"2022-05-01T10:00:00Z"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, q.Limit)
+	assert.Equal(t, "abc", q.Before)
+	assert.Equal(t, "POST", q.Method)
+	assert.Equal(t, time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC), q.Since)
+	assert.True(t, q.Until.IsZero())
+
+	_, err = parseRequestsQuery(url.Values{"limit": {"0"}})
+	assert.ErrorIs(t, err, errBadRequest)
+
+	_, err = parseRequestsQuery(url.Values{"until": {"yesterday"}})
+	assert.ErrorIs(t, err, errBadRequest)
+
+	q, err = parseRequestsQuery(url.Values{
+		"header":       {"X-GitHub-Event: push", "X-Hub-Signature-256"},
+		"param":        {"event=invoice.paid"},
+		"content_type": {"JSON"},
+		"ip":           {"10.0.0.1"},
+		"q":            {"Paid"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []keyValue{{"X-GitHub-Event", "push"}, {"X-Hub-Signature-256", ""}}, q.Headers)
+	assert.Equal(t, []keyValue{{"event", "invoice.paid"}}, q.Params)
+	assert.Equal(t, "json", q.ContentType)
+	assert.Equal(t, "10.0.0.1/32", q.IP.String())
+	assert.Equal(t, "paid", q.Text)
+
+	for _, form := range []url.Values{{"ip": {"localhost"}}, {"jsonpath": {"data.type"}}} {
+		_, err = parseRequestsQuery(form)
+		assert.ErrorIs(t, err, errBadRequest, form)
+	}
+}
+
+func TestRequestsQuerySearch(t *testing.T) {
+	req := &models.Request{
+		Method:      "POST",
+		Path:        "/foo/stripe/events",
+		RemoteAddr:  "10.1.2.3",
+		ContentType: "application/json",
+		Headers:     http.Header{"Stripe-Signature": {"t=1,v1=abc"}, "User-Agent": {"Stripe/1.0"}},
+		QueryData:   url.Values{"attempt": {"2"}},
+		FormData:    url.Values{},
+		Body:        []byte(`{"type":"invoice.paid","data":{"object":{"amount":1200,"lines":[{"id":"li_1"}]}}}`),
+	}
+
+	tbl := map[string]bool{
+		"path=stripe":                              true,
+		"path=github":                              false,
+		"header=stripe-signature":                  true,
+		"header=User-Agent: stripe":                true,
+		"header=User-Agent: curl":                  false,
+		"header=X-Missing":                         false,
+		"param=attempt":                            true,
+		"param=attempt=2":                          true,
+		"param=attempt=3":                          false,
+		"content_type=json":                        true,
+		"content_type=xml":                         false,
+		"ip=10.1.0.0/16":                           true,
+		"ip=10.1.2.4":                              false,
+		"q=INVOICE.PAID":                           true,
+		"q=refund":                                 false,
+		"jsonpath=$.type":                          true,
+		"jsonpath=$.id":                            false,
+		"jsonpath=$.type&jsonvalue=invoice.paid":   true,
+		"jsonpath=$.type&jsonvalue=invoice.failed": false,
+		"jsonpath=$..amount&jsonvalue=1200":        true,
+		"jsonpath=$.data.object.lines[*].id&jsonvalue=li_1": true,
+		"method=post&path=events&q=paid&ip=10.1.2.3":        true,
+	}
+
+	for query, expected := range tbl {
+		form, err := url.ParseQuery(query)
+		assert.NoError(t, err)
+
+		q, err := parseRequestsQuery(form)
+		assert.NoError(t, err, query)
+		assert.Equal(t, expected, q.Match(req), query)
+	}
+
+	q, err := parseRequestsQuery(url.Values{"jsonpath": {"$.type"}})
+	assert.NoError(t, err)
+	assert.False(t, q.Match(&models.Request{Body: []byte("type=invoice.paid")}))
+}
+
+func TestSearchFilters(t *testing.T) {
+	filters := searchFilters(url.Values{
+		"q":      {"paid"},
+		"method": {""},
+		"header": {"X-A", " "},
+		"before": {"abc"},
+		"secret": {"secret"},
+	})
+
+	assert.Equal(t, url.Values{"q": {"paid"}, "header": {"X-A"}}, filters)
+}
+
+func TestRequestsQueryMatch(t *testing.T) {
+	now := time.Now()
+
+	q := &requestsQuery{Method: "POST"}
+	assert.True(t, q.Match(&models.Request{Method: "POST"}))
+	assert.False(t, q.Match(&models.Request{Method: "GET"}))
+
+	q = &requestsQuery{Since: now.Add(-150 * time.Second), Until: now}
+	assert.True(t, q.Match(&models.Request{Created: now.Add(-time.Minute)}))
+	assert.False(t, q.Match(&models.Request{Created: now.Add(-3 * time.Minute)}))
+	assert.False(t, q.Match(&models.Request{Created: now}))
+}
@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 
@@ -10,25 +16,86 @@ import (
 )
 
 const (
-	maxRecent    = 10
-	urlParam     = "hook"
-	cookiePrefix = "hook_"
+	maxRecent     = 10
+	minTTL        = time.Minute
+	urlParam      = "hook"
+	requestParam  = "request"
+	fileParam     = "file"
+	cookiePrefix  = "hook_"
+	secretParam   = "secret"
+	bearerPrefix  = "Bearer "
+	pingInterval  = 30 * time.Second
+	streamRequest = "request"
+
+	headerOriginalSize = "X-Hooks-Original-Size"
 )
 
-// WebHome handle home page
-func WebHome(s store, t tpl, baseURL string, ttl int) http.HandlerFunc {
+// WebHome handle home page, signed in users get a list of their hooks
+func WebHome(s store, t tpl, baseURL string, ttl int, maxTTL int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := currentUser(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
 		recent, err := s.RecentHooks(maxRecent)
 		if err != nil {
 			renderError(w, r, err)
 			return
 		}
 
+		var (
+			hooks    []*models.Hook
+			counters map[string]int
+		)
+
+		if user != "" {
+			if hooks, err = s.UserHooks(user); err != nil {
+				renderError(w, r, err)
+				return
+			}
+
+			if counters, err = s.Counters(); err != nil {
+				renderError(w, r, err)
+				return
+			}
+		}
+
 		err = t.Execute(w, &views.Home{
 			Common: views.Common{
 				BaseURL: baseURL,
 				TTL:     ttl,
+				MaxTTL:  maxTTL,
 				Recent:  recent,
+				User:    user,
+			},
+			Hooks:    hooks,
+			Counters: counters,
+		})
+		if err != nil {
+			renderError(w, r, err)
+		}
+	}
+}
+
+// WebLogin handle sign in and sign up page
+func WebLogin(s store, t tpl, baseURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := currentUser(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		if user != "" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+
+		err = t.Execute(w, &views.Home{
+			Common: views.Common{
+				BaseURL: baseURL,
 			},
 		})
 		if err != nil {
@@ -38,7 +105,7 @@ func WebHome(s store, t tpl, baseURL string, ttl int) http.HandlerFunc {
 }
 
 // WebInspect handle hook page
-func WebInspect(s store, t tpl, baseURL string, ttl int) http.HandlerFunc {
+func WebInspect(s store, t tpl, baseURL string, ttl int, maxTTL int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		hook, err := s.Hook(chi.URLParam(r, urlParam))
 		if err != nil {
@@ -51,18 +118,52 @@ func WebInspect(s store, t tpl, baseURL string, ttl int) http.HandlerFunc {
 			return
 		}
 
-		if !checkAccess(r, hook) {
+		user, err := currentUser(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		owned := hook.Owner != "" && hook.Owner == user
+
+		if !owned && !checkAccess(r, hook) {
 			renderError(w, r, errNotFound)
 			return
 		}
 
-		requests, err := s.Requests(hook.Name)
+		expires := hook.ExpiresAt(time.Duration(ttl) * time.Hour)
+
+		// shared access link of private hook, keep the secret in a cookie and drop it from the address bar.
+		// Public hooks keep the secret in the address, the page passes it to settings calls
+		if hook.Private && r.URL.Query().Get(secretParam) != "" && hasSecret(r, hook) {
+			maxAge := sessionTTL // owned hooks never expire
+			if !expires.IsZero() {
+				maxAge = time.Until(expires)
+			}
+
+			setAccessCookie(w, hook, maxAge)
+
+			u := *r.URL
+			query := u.Query()
+			query.Del(secretParam)
+			u.RawQuery = query.Encode()
+
+			http.Redirect(w, r, u.String(), http.StatusFound)
+
+			return
+		}
+
+		q, err := parseRequestsQuery(r.URL.Query())
 		if err != nil {
 			renderError(w, r, err)
 			return
 		}
 
-		hook.Requests = requests
+		requests, next, err := requestsPage(s, hook.Name, q)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
 
 		recent, err := s.RecentHooks(maxRecent)
 		if err != nil {
@@ -70,13 +171,24 @@ func WebInspect(s store, t tpl, baseURL string, ttl int) http.HandlerFunc {
 			return
 		}
 
+		configurable := owned || hasSecret(r, hook)
+
 		err = t.Execute(w, &views.Hook{
 			Common: views.Common{
 				BaseURL: baseURL,
 				TTL:     ttl,
+				MaxTTL:  maxTTL,
 				Recent:  recent,
+				User:    user,
 			},
-			Hook: hook,
+			Hook:         hook,
+			Owned:        owned,
+			Configurable: configurable,
+			Expires:      expires,
+			Requests:     views.NewRequests(baseURL, hook.Name, configurable, requests),
+			Before:       q.Before,
+			Next:         next,
+			Filters:      searchFilters(r.URL.Query()),
 		})
 		if err != nil {
 			renderError(w, r, err)
@@ -84,15 +196,126 @@ func WebInspect(s store, t tpl, baseURL string, ttl int) http.HandlerFunc {
 	}
 }
 
+// WebStream handle server-sent events stream of hook requests
+func WebStream(s store, sub subscriber, t tpl, baseURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := accessibleHook(s, r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		configurable, err := canConfigure(s, r, hook)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			renderError(w, r, fmt.Errorf("streaming is not supported"))
+			return
+		}
+
+		ch, cancel := sub.Subscribe(hook.Name)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case req, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				var buf bytes.Buffer
+				if err := t.Execute(&buf, &views.Request{BaseURL: baseURL, Hook: hook.Name, Request: req, Configurable: configurable}); err != nil {
+					log.Printf("[WARN] failed to render request %s, %+v", req.Name, err)
+					continue
+				}
+
+				writeEvent(w, streamRequest, req.Name, buf.Bytes())
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case <-r.Context().Done():
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+// checkAccess reports whether the hook is accessible by the request.
+// Private hooks require the hook secret passed in the cookie, the bearer token or the secret query parameter
 func checkAccess(r *http.Request, hook *models.Hook) bool {
 	if !hook.Private {
 		return true
 	}
 
-	c, err := r.Cookie(cookiePrefix + hook.Name)
-	if c == nil || err != nil {
+	return hasSecret(r, hook)
+}
+
+// hasSecret reports whether the request provides the hook secret
+func hasSecret(r *http.Request, hook *models.Hook) bool {
+	if hook.Secret == "" {
 		return false
 	}
 
-	return c.Value == hook.Secret
+	for _, secret := range requestSecrets(r, hook) {
+		if secret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(hook.Secret)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestSecrets returns hook secrets provided by the request
+func requestSecrets(r *http.Request, hook *models.Hook) []string {
+	secrets := []string{r.URL.Query().Get(secretParam)}
+
+	if auth := r.Header.Get("Authorization"); len(auth) > len(bearerPrefix) && strings.EqualFold(auth[:len(bearerPrefix)], bearerPrefix) {
+		secrets = append(secrets, strings.TrimSpace(auth[len(bearerPrefix):]))
+	}
+
+	if c, err := r.Cookie(cookiePrefix + hook.Name); err == nil {
+		secrets = append(secrets, c.Value)
+	}
+
+	return secrets
+}
+
+// setAccessCookie keeps the secret of private hook in the browser until the hook expires
+func setAccessCookie(w http.ResponseWriter, hook *models.Hook, maxAge time.Duration) {
+	if !hook.Private || maxAge < time.Second {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookiePrefix + hook.Name,
+		Value:    hook.Secret,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// writeEvent writes a server-sent event, multiline data is split into several data fields
+func writeEvent(w http.ResponseWriter, event string, id string, data []byte) {
+	fmt.Fprintf(w, "event: %s\nid: %s\n", event, id)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(w, "data: %s\n", strings.TrimSuffix(line, "\r"))
+	}
+
+	fmt.Fprint(w, "\n")
 }
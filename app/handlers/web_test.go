@@ -4,12 +4,15 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/views"
 )
 
 func TestWebHome(t *testing.T) {
@@ -24,7 +27,7 @@ func TestWebHome(t *testing.T) {
 		},
 	}
 
-	handler := WebHome(s, tmpl, "", 48)
+	handler := WebHome(s, tmpl, "", 48, 168)
 
 	router := chi.NewRouter()
 	router.Get("/", handler)
@@ -47,7 +50,7 @@ func TestWebHomeError(t *testing.T) {
 		},
 	}
 
-	handler := WebHome(s, tmpl, "", 48)
+	handler := WebHome(s, tmpl, "", 48, 168)
 
 	router := chi.NewRouter()
 	router.Get("/", handler)
@@ -71,10 +74,10 @@ func TestWebInspect(t *testing.T) {
 		RecentHooksFunc: func(max int) ([]*models.Hook, error) {
 			return nil, nil
 		},
-		RequestsFunc: func(hook string) ([]*models.Request, error) {
+		RequestsPageFunc: func(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
 			assert.Equal(t, "foo", hook)
 
-			return nil, nil
+			return nil, "", nil
 		},
 	}
 	tmpl := &tplMock{
@@ -83,7 +86,7 @@ func TestWebInspect(t *testing.T) {
 		},
 	}
 
-	handler := WebInspect(s, tmpl, "", 48)
+	handler := WebInspect(s, tmpl, "", 48, 168)
 
 	router := chi.NewRouter()
 	router.Get("/{hook}", handler)
@@ -108,10 +111,10 @@ func TestWebInspectPrivate(t *testing.T) {
 		RecentHooksFunc: func(max int) ([]*models.Hook, error) {
 			return nil, nil
 		},
-		RequestsFunc: func(hook string) ([]*models.Request, error) {
+		RequestsPageFunc: func(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
 			assert.Equal(t, "private", hook)
 
-			return nil, nil
+			return nil, "", nil
 		},
 	}
 	tmpl := &tplMock{
@@ -120,7 +123,7 @@ func TestWebInspectPrivate(t *testing.T) {
 		},
 	}
 
-	handler := WebInspect(s, tmpl, "", 48)
+	handler := WebInspect(s, tmpl, "", 48, 168)
 
 	router := chi.NewRouter()
 	router.Get("/{hook}", handler)
@@ -149,7 +152,7 @@ func TestWebInspectPrivateError(t *testing.T) {
 		},
 	}
 
-	handler := WebInspect(s, tmpl, "", 48)
+	handler := WebInspect(s, tmpl, "", 48, 168)
 
 	router := chi.NewRouter()
 	router.Get("/{hook}", handler)
@@ -159,3 +162,158 @@ func TestWebInspectPrivateError(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNotFound, w.Code) // name - secret missmatch
 }
+
+func TestWebInspectShareLink(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name:    name,
+				Private: true,
+				Secret:  "secret",
+				Created: time.Now(),
+				Expires: time.Now().Add(time.Hour),
+			}, nil
+		},
+	}
+	tmpl := &tplMock{
+		ExecuteFunc: func(wr io.Writer, data interface{}) error {
+			return nil
+		},
+	}
+
+	handler := WebInspect(s, tmpl, "", 48, 168)
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", handler)
+
+	w, err := testRequest(router, http.MethodGet, "/foo?secret=secret&before=bar", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/foo?before=bar", w.Header().Get("Location"))
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "hook_foo=secret")
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=3599")
+
+	w, err = testRequest(router, http.MethodGet, "/foo?secret=wrong", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+}
+
+func TestWebInspectShareLinkPublic(t *testing.T) {
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{Name: name, Secret: "secret", Created: time.Now()}, nil
+		},
+		RequestsPageFunc: func(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+			return nil, "", nil
+		},
+		RecentHooksFunc: func(max int) ([]*models.Hook, error) {
+			return nil, nil
+		},
+	}
+
+	var data *views.Hook
+
+	tmpl := &tplMock{
+		ExecuteFunc: func(wr io.Writer, d interface{}) error {
+			data = d.(*views.Hook)
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", WebInspect(s, tmpl, "", 48, 168))
+
+	// public hooks keep the secret in the address instead of a cookie
+	w, err := testRequest(router, http.MethodGet, "/foo?secret=secret", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+	if assert.NotNil(t, data) {
+		assert.True(t, data.Configurable)
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	hook := &models.Hook{Name: "foo", Private: true, Secret: "secret"}
+
+	tbl := []struct {
+		name   string
+		req    func(r *http.Request)
+		access bool
+	}{
+		{"none", func(r *http.Request) {}, false},
+		{"cookie", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "hook_foo", Value: "secret"}) }, true},
+		{"wrong cookie", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "hook_foo", Value: "wrong"}) }, false},
+		{"other hook cookie", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "hook_bar", Value: "secret"}) }, false},
+		{"bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, true},
+		{"lowercase bearer", func(r *http.Request) { r.Header.Set("Authorization", "bearer secret") }, true},
+		{"wrong bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }, false},
+		{"basic", func(r *http.Request) { r.Header.Set("Authorization", "Basic secret") }, false},
+		{"query", func(r *http.Request) { r.URL.RawQuery = "secret=secret" }, true},
+		{"empty query", func(r *http.Request) { r.URL.RawQuery = "secret=" }, false},
+	}
+
+	for _, tt := range tbl {
+		r := httptest.NewRequest(http.MethodGet, "/api/hooks/foo", nil)
+		tt.req(r)
+
+		assert.Equal(t, tt.access, checkAccess(r, hook), tt.name)
+	}
+
+	assert.True(t, checkAccess(httptest.NewRequest(http.MethodGet, "/", nil), &models.Hook{Name: "public"}))
+}
+
+func TestWebStream(t *testing.T) {
+	ch := make(chan *models.Request, 1)
+	ch <- &models.Request{Name: "req"}
+	close(ch) // stream ends when subscription is closed
+
+	s := &storeMock{
+		HookFunc: func(name string) (*models.Hook, error) {
+			return &models.Hook{
+				Name: name,
+			}, nil
+		},
+	}
+	sub := &subscriberMock{
+		SubscribeFunc: func(hook string) (<-chan *models.Request, func()) {
+			assert.Equal(t, "foo", hook)
+
+			return ch, func() {}
+		},
+	}
+	tmpl := &tplMock{
+		ExecuteFunc: func(wr io.Writer, data interface{}) error {
+			_, err := wr.Write([]byte("<div>\n" + data.(*views.Request).Request.Name + "\n</div>"))
+			return err
+		},
+	}
+
+	handler := WebStream(s, sub, tmpl, "")
+
+	router := chi.NewRouter()
+	router.Get("/{hook}", handler)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		w, err := testRequest(router, http.MethodGet, "/foo", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "event: request\nid: req\ndata: <div>\ndata: req\ndata: </div>\n\n", w.Body.String())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream is not closed")
+	}
+}
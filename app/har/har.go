@@ -0,0 +1,328 @@
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+const (
+	harVersion  = "1.2"
+	creatorName = "hooks"
+	httpVersion = "HTTP/1.1"
+)
+
+// HAR is an HTTP Archive document, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log is the root of exported data
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator is the application exported the log
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is an exported request with its response
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"` // milliseconds
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	Comment         string    `json:"comment,omitempty"`
+}
+
+// Request is an exported request
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+	Comment     string      `json:"comment,omitempty"`
+}
+
+// Response is an exported response
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+	Comment     string      `json:"comment,omitempty"`
+}
+
+// Cookie is an exported cookie
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NameValue is an exported header or query parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is an exported request body
+type PostData struct {
+	MimeType string  `json:"mimeType"`
+	Params   []Param `json:"params"`
+	Text     string  `json:"text"`
+	Encoding string  `json:"encoding,omitempty"` // not in the spec, used by some tools like Content.Encoding
+	Comment  string  `json:"comment,omitempty"`
+}
+
+// Param is an exported form field or file
+type Param struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// Content is an exported response body
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// Timings are durations of the exchange phases in milliseconds, -1 if not applicable
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// New returns an archive of the hook requests, the hook URL is prefixed by baseURL.
+// The response of an entry is the upstream one of forwarded requests, otherwise the mock response
+// the request was answered with or the default one echoing the request
+func New(baseURL string, version string, requests []*models.Request) *HAR {
+	entries := make([]Entry, 0, len(requests))
+
+	for _, req := range requests {
+		entries = append(entries, newEntry(baseURL, req))
+	}
+
+	return &HAR{
+		Log: Log{
+			Version: harVersion,
+			Creator: Creator{Name: creatorName, Version: version},
+			Entries: entries,
+		},
+	}
+}
+
+func newEntry(baseURL string, req *models.Request) Entry {
+	entry := Entry{
+		StartedDateTime: req.Created,
+		Request:         newRequest(baseURL, req),
+		Timings:         Timings{Blocked: -1, DNS: -1, Connect: -1},
+	}
+
+	switch {
+	case req.Upstream != nil:
+		entry.Response = newUpstreamResponse(req)
+		entry.Timings.Wait = milliseconds(req.Upstream.Latency)
+		entry.Comment = "forwarded to " + req.Upstream.URL
+	case req.Mock != nil:
+		entry.Response = newMockResponse(req.Mock)
+		entry.Timings.Wait = milliseconds(req.Mock.Latency)
+		entry.Comment = "mock response of the hook"
+	default:
+		entry.Response = Response{
+			Status:  http.StatusOK,
+			Content: Content{MimeType: "application/json", Comment: "the captured request as JSON"},
+		}
+	}
+
+	entry.Response.StatusText = http.StatusText(entry.Response.Status)
+	entry.Response.HTTPVersion = httpVersion
+	entry.Response.HeadersSize = -1
+
+	if entry.Response.Cookies == nil {
+		entry.Response.Cookies = []Cookie{}
+	}
+
+	if entry.Response.Headers == nil {
+		entry.Response.Headers = []NameValue{}
+	}
+
+	entry.Time = entry.Timings.Send + entry.Timings.Wait + entry.Timings.Receive
+
+	return entry
+}
+
+func newRequest(baseURL string, req *models.Request) Request {
+	u := baseURL + req.Path
+	if req.Query != "" {
+		u += "?" + req.Query
+	}
+
+	result := Request{
+		Method:      req.Method,
+		URL:         u,
+		HTTPVersion: httpVersion,
+		Cookies:     []Cookie{},
+		Headers:     nameValues(req.Headers),
+		QueryString: nameValues(req.QueryData),
+		HeadersSize: -1,
+		BodySize:    req.BodySize,
+	}
+
+	for _, c := range (&http.Request{Header: req.Headers}).Cookies() {
+		result.Cookies = append(result.Cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	if req.BodySize == 0 && len(req.FormData) == 0 {
+		return result
+	}
+
+	postData := &PostData{
+		MimeType: req.Headers.Get("Content-Type"),
+		Params:   []Param{},
+	}
+
+	if req.IsBinary() {
+		postData.Text = base64.StdEncoding.EncodeToString(req.Body)
+		postData.Encoding = "base64"
+		postData.Comment = "base64 encoded"
+	} else {
+		postData.Text = string(req.Body)
+	}
+
+	if req.BodyTruncated {
+		result.Comment = "body is truncated to the stored part"
+	}
+
+	for _, nv := range nameValues(req.FormData) {
+		postData.Params = append(postData.Params, Param{Name: nv.Name, Value: nv.Value})
+	}
+
+	for _, f := range req.Files {
+		postData.Params = append(postData.Params, Param{Name: f.Field, FileName: f.Filename, ContentType: f.ContentType})
+	}
+
+	result.PostData = postData
+
+	return result
+}
+
+func newUpstreamResponse(req *models.Request) Response {
+	reply := req.Upstream
+
+	switch {
+	case req.BodyTruncated:
+		return newErrorResponse(http.StatusRequestEntityTooLarge, reply.Error)
+	case reply.Error != "":
+		return newErrorResponse(http.StatusBadGateway, reply.Error)
+	case reply.BodyTruncated:
+		return newErrorResponse(http.StatusBadGateway, "Upstream response exceeds max body size")
+	}
+
+	resp := Response{
+		Status:   reply.Status,
+		Headers:  nameValues(reply.Headers),
+		Content:  newContent(reply.Body, reply.Headers.Get("Content-Type"), reply.IsBinary()),
+		BodySize: reply.BodySize,
+	}
+
+	resp.Content.Size = reply.BodySize
+	resp.RedirectURL = reply.Headers.Get("Location")
+
+	return resp
+}
+
+// newErrorResponse returns the error response the sender got instead of the upstream one
+func newErrorResponse(status int, message string) Response {
+	text, _ := json.Marshal(map[string]string{"error": message})
+
+	return Response{
+		Status:   status,
+		Content:  Content{Size: int64(len(text)), MimeType: "application/json", Text: string(text)},
+		BodySize: int64(len(text)),
+		Comment:  message,
+	}
+}
+
+// newMockResponse returns the mock response recorded at capture
+func newMockResponse(reply *models.Reply) Response {
+	if reply.Error != "" {
+		return newErrorResponse(http.StatusInternalServerError, reply.Error)
+	}
+
+	return Response{
+		Status:   reply.Status,
+		Headers:  nameValues(reply.Headers),
+		Content:  newContent(reply.Body, reply.Headers.Get("Content-Type"), reply.IsBinary()),
+		BodySize: reply.BodySize,
+	}
+}
+
+func newContent(body []byte, mimeType string, binary bool) Content {
+	content := Content{
+		Size:     int64(len(body)),
+		MimeType: mimeType,
+	}
+
+	if binary {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	} else {
+		content.Text = string(body)
+	}
+
+	return content
+}
+
+// nameValues flattens multi-valued map sorted by names
+func nameValues(values map[string][]string) []NameValue {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	result := make([]NameValue, 0, len(values))
+
+	for _, name := range names {
+		for _, value := range values[name] {
+			result = append(result, NameValue{Name: name, Value: value})
+		}
+	}
+
+	return result
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,127 @@
+package har
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+func TestNew(t *testing.T) {
+	created := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	form := &models.Request{
+		Method:   http.MethodPost,
+		Path:     "/foo/events",
+		Query:    "x=1",
+		Body:     []byte("event=invoice.paid&id=1"),
+		BodySize: 23,
+		Headers: http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+			"Cookie":       {"a=1; b=2"},
+		},
+		QueryData: url.Values{"x": {"1"}},
+		FormData:  url.Values{"event": {"invoice.paid"}, "id": {"1"}},
+		Upstream: &models.Reply{
+			URL:      "http://localhost:3000",
+			Status:   http.StatusCreated,
+			Headers:  http.Header{"Content-Type": {"text/plain"}},
+			Body:     []byte("created"),
+			BodySize: 7,
+			Latency:  1500 * time.Microsecond,
+		},
+		Created: created,
+	}
+
+	get := &models.Request{
+		Method:  http.MethodGet,
+		Path:    "/foo",
+		Headers: http.Header{},
+		Created: created.Add(time.Second),
+	}
+
+	mock := &models.Response{
+		Status:      http.StatusAccepted,
+		ContentType: "text/plain",
+		Body:        "{{ .Method }}",
+		Delay:       20,
+	}
+	get.Mock = mock.Reply(get)
+
+	doc := New("http://localhost:8080", "1.2.3", []*models.Request{form, get})
+
+	assert.Equal(t, "1.2", doc.Log.Version)
+	assert.Equal(t, Creator{Name: "hooks", Version: "1.2.3"}, doc.Log.Creator)
+	require.Len(t, doc.Log.Entries, 2)
+
+	e := doc.Log.Entries[0]
+	assert.Equal(t, created, e.StartedDateTime)
+	assert.Equal(t, "http://localhost:8080/foo/events?x=1", e.Request.URL)
+	assert.Equal(t, []NameValue{{"x", "1"}}, e.Request.QueryString)
+	assert.Equal(t, []Cookie{{"a", "1"}, {"b", "2"}}, e.Request.Cookies)
+	assert.Equal(t, int64(23), e.Request.BodySize)
+	require.NotNil(t, e.Request.PostData)
+	assert.Equal(t, "application/x-www-form-urlencoded", e.Request.PostData.MimeType)
+	assert.Equal(t, "event=invoice.paid&id=1", e.Request.PostData.Text)
+	assert.Equal(t, []Param{{Name: "event", Value: "invoice.paid"}, {Name: "id", Value: "1"}}, e.Request.PostData.Params)
+
+	assert.Equal(t, http.StatusCreated, e.Response.Status)
+	assert.Equal(t, "Created", e.Response.StatusText)
+	assert.Equal(t, Content{Size: 7, MimeType: "text/plain", Text: "created"}, e.Response.Content)
+	assert.Equal(t, 1.5, e.Timings.Wait)
+	assert.Equal(t, 1.5, e.Time)
+
+	// the mock response recorded at capture
+	e = doc.Log.Entries[1]
+	assert.Nil(t, e.Request.PostData)
+	assert.Equal(t, http.StatusAccepted, e.Response.Status)
+	assert.Equal(t, "GET", e.Response.Content.Text)
+	assert.Equal(t, []NameValue{{"Content-Type", "text/plain"}}, e.Response.Headers)
+	assert.Equal(t, 20.0, e.Time)
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"startedDateTime":"2022-05-01T10:00:00Z"`)
+	assert.Contains(t, string(data), `"cache":{}`)
+}
+
+func TestNewResponses(t *testing.T) {
+	req := &models.Request{
+		Method:   http.MethodPut,
+		Path:     "/foo",
+		Body:     []byte{0x00, 0xff},
+		BodySize: 2,
+		Headers:  http.Header{"Content-Type": {"application/octet-stream"}},
+		Upstream: models.NewFailedReply("http://localhost:3000", errors.New("connection refused"), 0),
+	}
+
+	e := New("", "", []*models.Request{req}).Log.Entries[0]
+
+	assert.Equal(t, "AP8=", e.Request.PostData.Text)
+	assert.Equal(t, "base64", e.Request.PostData.Encoding)
+	assert.Equal(t, http.StatusBadGateway, e.Response.Status)
+	assert.Equal(t, `{"error":"connection refused"}`, e.Response.Content.Text)
+
+	req.Upstream = nil
+
+	e = New("", "", []*models.Request{req}).Log.Entries[0]
+
+	assert.Equal(t, http.StatusOK, e.Response.Status)
+	assert.Equal(t, "application/json", e.Response.Content.MimeType)
+	assert.Equal(t, []NameValue{}, e.Response.Headers)
+
+	// failed rendering of the mock response
+	req.Mock = (&models.Response{Status: http.StatusOK, Body: "{{ .Missing }}"}).Reply(req)
+
+	e = New("", "", []*models.Request{req}).Log.Entries[0]
+
+	assert.Equal(t, http.StatusInternalServerError, e.Response.Status)
+	assert.Contains(t, e.Response.Comment, "Missing")
+}
@@ -0,0 +1,102 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidArchive is returned when the archive or its entry can not be imported
+var ErrInvalidArchive = errors.New("invalid HAR")
+
+// Decode reads the archive, e.g. saved from the browser developer tools
+func Decode(r io.Reader) (*HAR, error) {
+	var doc struct {
+		Log *Log `json:"log"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+
+	if doc.Log == nil {
+		return nil, fmt.Errorf("%w: log is missing", ErrInvalidArchive)
+	}
+
+	return &HAR{Log: *doc.Log}, nil
+}
+
+// HTTPRequest returns the request of the entry as it was sent
+func (e *Entry) HTTPRequest() (*http.Request, error) {
+	body, fromParams, err := e.Request.body()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequest(e.Request.Method, e.Request.URL, ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+
+	for _, h := range e.Request.Headers {
+		// skip pseudo headers of HTTP/2 like :authority
+		if strings.HasPrefix(h.Name, ":") {
+			continue
+		}
+
+		r.Header.Add(h.Name, h.Value)
+	}
+
+	if r.Header.Get("Cookie") == "" {
+		for _, c := range e.Request.Cookies {
+			r.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+
+	switch {
+	case fromParams:
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	case e.Request.PostData != nil && e.Request.PostData.MimeType != "" && r.Header.Get("Content-Type") == "":
+		r.Header.Set("Content-Type", e.Request.PostData.MimeType)
+	}
+
+	r.ContentLength = int64(len(body))
+
+	return r, nil
+}
+
+// body returns the posted body, form params are URL encoded when the text is omitted
+func (r *Request) body() (body []byte, fromParams bool, err error) {
+	postData := r.PostData
+
+	switch {
+	case postData == nil:
+		return nil, false, nil
+	case postData.Encoding == "base64":
+		body, err = base64.StdEncoding.DecodeString(postData.Text)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: postData is not base64 encoded: %s", ErrInvalidArchive, err)
+		}
+
+		return body, false, nil
+	case postData.Text != "" || len(postData.Params) == 0:
+		return []byte(postData.Text), false, nil
+	}
+
+	form := url.Values{}
+
+	for _, p := range postData.Params {
+		if p.FileName == "" {
+			form.Add(p.Name, p.Value)
+		}
+	}
+
+	return []byte(form.Encode()), true, nil
+}
@@ -0,0 +1,138 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+const testArchive = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "entries": [
+      {
+        "startedDateTime": "2022-05-01T10:00:00.123Z",
+        "time": 12.5,
+        "request": {
+          "method": "POST",
+          "url": "https://example.com/api/events?x=1",
+          "httpVersion": "HTTP/2.0",
+          "headers": [
+            {"name": ":authority", "value": "example.com"},
+            {"name": "content-type", "value": "application/json"}
+          ],
+          "queryString": [{"name": "x", "value": "1"}],
+          "cookies": [{"name": "session", "value": "abc"}],
+          "postData": {"mimeType": "application/json", "text": "{\"id\":1}"},
+          "headersSize": -1,
+          "bodySize": 8
+        },
+        "response": {"status": 200, "content": {"size": 0, "mimeType": "text/plain"}},
+        "cache": {},
+        "timings": {"send": 1, "wait": 10, "receive": 1.5}
+      },
+      {
+        "startedDateTime": "2022-05-01T10:00:01+02:00",
+        "request": {
+          "method": "POST",
+          "url": "https://example.com/login",
+          "headers": [],
+          "postData": {
+            "mimeType": "multipart/form-data",
+            "params": [{"name": "user", "value": "john"}, {"name": "avatar", "fileName": "a.png"}]
+          }
+        },
+        "response": {"status": 302}
+      }
+    ]
+  }
+}`
+
+func TestDecode(t *testing.T) {
+	doc, err := Decode(strings.NewReader(testArchive))
+	require.NoError(t, err)
+	require.Len(t, doc.Log.Entries, 2)
+
+	e := doc.Log.Entries[0]
+	assert.Equal(t, time.Date(2022, 5, 1, 10, 0, 0, 123000000, time.UTC), e.StartedDateTime.UTC())
+
+	r, err := e.HTTPRequest()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "/api/events", r.URL.Path)
+	assert.Equal(t, "x=1", r.URL.RawQuery)
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, "session=abc", r.Header.Get("Cookie"))
+	assert.NotContains(t, r.Header, ":authority")
+
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1}`, string(body))
+
+	// params are encoded when the text is omitted, files are skipped
+	r, err = doc.Log.Entries[1].HTTPRequest()
+	require.NoError(t, err)
+
+	assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+	require.NoError(t, r.ParseForm())
+	assert.Equal(t, "john", r.PostForm.Get("user"))
+	assert.NotContains(t, r.PostForm, "avatar")
+}
+
+func TestDecodeExported(t *testing.T) {
+	req := &models.Request{
+		Method:   http.MethodPut,
+		Path:     "/foo",
+		Body:     []byte{0x00, 0xff},
+		BodySize: 2,
+		Headers:  http.Header{"Content-Type": {"application/octet-stream"}},
+		Created:  time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	data, err := json.Marshal(New("http://localhost:8080", "", []*models.Request{req}))
+	require.NoError(t, err)
+
+	doc, err := Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, doc.Log.Entries, 1)
+	assert.Equal(t, req.Created, doc.Log.Entries[0].StartedDateTime)
+
+	r, err := doc.Log.Entries[0].HTTPRequest()
+	require.NoError(t, err)
+
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, req.Body, body)
+	assert.Equal(t, "http://localhost:8080/foo", r.URL.String())
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"[]",
+		`{"entries": []}`,
+		`{"log": {"entries": [{"startedDateTime": "yesterday"}]}}`,
+	} {
+		_, err := Decode(strings.NewReader(data))
+		assert.ErrorIs(t, err, ErrInvalidArchive, data)
+	}
+
+	e := Entry{Request: Request{Method: "GET", URL: "://"}}
+	_, err := e.HTTPRequest()
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+
+	e = Entry{Request: Request{Method: "POST", URL: "/", PostData: &PostData{Text: "!", Encoding: "base64"}}}
+	_, err = e.HTTPRequest()
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
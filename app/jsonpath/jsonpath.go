@@ -0,0 +1,209 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath is returned for expressions out of the supported syntax
+var ErrInvalidPath = errors.New("invalid jsonpath")
+
+// step is a single selector of the path
+type step struct {
+	key       string // object key, empty with wildcard or index
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool // applies to all descendants, the ".." selector
+}
+
+// Path is a compiled JSONPath expression.
+// The supported subset is the root "$", child ".key" and "['key']", array index "[0]",
+// wildcard ".*" and "[*]" and recursive descent "..key"
+type Path struct {
+	steps []step
+}
+
+// Parse compiles the expression
+func Parse(expr string) (*Path, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("%w: %q must start with $", ErrInvalidPath, expr)
+	}
+
+	p := &Path{}
+	rest := expr[1:]
+
+	for rest != "" {
+		var (
+			s   step
+			err error
+		)
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			s.recursive = true
+			rest = rest[2:]
+
+			if strings.HasPrefix(rest, "[") {
+				s, rest, err = parseBracket(rest, s)
+			} else {
+				s, rest = parseDot(rest, s)
+			}
+		case strings.HasPrefix(rest, "."):
+			s, rest = parseDot(rest[1:], s)
+		case strings.HasPrefix(rest, "["):
+			s, rest, err = parseBracket(rest, s)
+		default:
+			err = fmt.Errorf("unexpected %q", rest)
+		}
+
+		if err == nil && !s.wildcard && !s.isIndex && s.key == "" {
+			err = errors.New("empty key")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s", ErrInvalidPath, expr, err)
+		}
+
+		p.steps = append(p.steps, s)
+	}
+
+	return p, nil
+}
+
+// parseDot parses the key following the dot up to the next selector
+func parseDot(rest string, s step) (step, string) {
+	end := strings.IndexAny(rest, ".[")
+	if end == -1 {
+		end = len(rest)
+	}
+
+	if rest[:end] == "*" {
+		s.wildcard = true
+	} else {
+		s.key = rest[:end]
+	}
+
+	return s, rest[end:]
+}
+
+// parseBracket parses the quoted key, index or wildcard in brackets
+func parseBracket(rest string, s step) (step, string, error) {
+	end := strings.Index(rest, "]")
+	if end == -1 {
+		return s, rest, errors.New("unclosed bracket")
+	}
+
+	inner := strings.TrimSpace(rest[1:end])
+	rest = rest[end+1:]
+
+	switch {
+	case inner == "*":
+		s.wildcard = true
+	case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+		s.key = inner[1 : len(inner)-1]
+	default:
+		i, err := strconv.Atoi(inner)
+		if err != nil {
+			return s, rest, fmt.Errorf("invalid index %q", inner)
+		}
+
+		s.index = i
+		s.isIndex = true
+	}
+
+	return s, rest, nil
+}
+
+// Select returns values of the decoded JSON document matched by the path
+func (p *Path) Select(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+
+	for _, s := range p.steps {
+		var next []interface{}
+
+		for _, node := range nodes {
+			if s.recursive {
+				for _, n := range descendants(node) {
+					next = append(next, s.apply(n)...)
+				}
+
+				continue
+			}
+
+			next = append(next, s.apply(node)...)
+		}
+
+		nodes = next
+	}
+
+	return nodes
+}
+
+// apply returns children of the node matched by the step
+func (s step) apply(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			return values(v)
+		}
+
+		if child, ok := v[s.key]; ok && !s.isIndex {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return v
+		}
+
+		i := s.index
+		if i < 0 {
+			i += len(v)
+		}
+
+		if s.isIndex && i >= 0 && i < len(v) {
+			return []interface{}{v[i]}
+		}
+	}
+
+	return nil
+}
+
+// descendants returns the node with all nested values
+func descendants(node interface{}) []interface{} {
+	result := []interface{}{node}
+
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for _, child := range values(v) {
+			result = append(result, descendants(child)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			result = append(result, descendants(child)...)
+		}
+	}
+
+	return result
+}
+
+// values returns object values ordered by keys
+func values(obj map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	result := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, obj[key])
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `{
+	"type": "invoice.paid",
+	"data": {
+		"object": {
+			"id": "in_1",
+			"amount": 1200,
+			"lines": [{"price": {"id": "p_1"}}, {"price": {"id": "p_2"}}],
+			"metadata": {"order id": "42"}
+		}
+	}
+}`
+
+func TestSelect(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(testDoc))
+	dec.UseNumber()
+
+	var doc interface{}
+	require.NoError(t, dec.Decode(&doc))
+
+	tbl := map[string][]interface{}{
+		"$.type":                             {"invoice.paid"},
+		"$.data.object.amount":               {json.Number("1200")},
+		"$['data']['object'].id":             {"in_1"},
+		`$.data.object.metadata["order id"]`: {"42"},
+		"$.data.object.lines[1].price.id":    {"p_2"},
+		"$.data.object.lines[-1].price.id":   {"p_2"},
+		"$.data.object.lines[*].price.id":    {"p_1", "p_2"},
+		"$..price.id":                        {"p_1", "p_2"},
+		"$.data.*.id":                        {"in_1"},
+		"$.data.object.lines[5]":             nil,
+		"$.missing":                          nil,
+		"$.type.nested":                      nil,
+	}
+
+	for expr, expected := range tbl {
+		p, err := Parse(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, p.Select(doc), expr)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "type", "$.", "$[", "$[abc]", "$..", "$.a[]", "$['a']x"} {
+		_, err := Parse(expr)
+		assert.ErrorIs(t, err, ErrInvalidPath, expr)
+	}
+
+	p, err := Parse("$")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"doc"}, p.Select("doc"))
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultForwardTimeout = 10 * time.Second
+	maxForwardTimeout     = 30 * time.Second
+)
+
+// Forward is an upstream the hook forwards incoming requests to
+type Forward struct {
+	URL     string `json:"url"`
+	Timeout int    `json:"timeout"` // milliseconds, zero means default
+}
+
+// Validate checks that requests could be forwarded to the upstream
+func (f *Forward) Validate() error {
+	u, err := url.Parse(f.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https url")
+	}
+
+	if f.Timeout < 0 || f.TimeoutDuration() > maxForwardTimeout {
+		return fmt.Errorf("timeout must be between 0 and %d ms", maxForwardTimeout.Milliseconds())
+	}
+
+	return nil
+}
+
+// TimeoutDuration returns timeout of the upstream exchange as duration
+func (f *Forward) TimeoutDuration() time.Duration {
+	if f.Timeout == 0 {
+		return defaultForwardTimeout
+	}
+
+	return time.Duration(f.Timeout) * time.Millisecond
+}
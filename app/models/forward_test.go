@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardValidate(t *testing.T) {
+	f := &Forward{URL: "http://localhost:8080/webhooks", Timeout: 500}
+	assert.NoError(t, f.Validate())
+	assert.Equal(t, 500*time.Millisecond, f.TimeoutDuration())
+
+	f = &Forward{URL: "https://example.com"}
+	assert.NoError(t, f.Validate())
+	assert.Equal(t, defaultForwardTimeout, f.TimeoutDuration())
+
+	f = &Forward{URL: "localhost:8080"}
+	assert.Error(t, f.Validate())
+
+	f = &Forward{URL: "ftp://example.com"}
+	assert.Error(t, f.Validate())
+
+	f = &Forward{URL: "http://localhost", Timeout: 60000}
+	assert.Error(t, f.Validate())
+}
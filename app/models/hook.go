@@ -1,7 +1,12 @@
 package models
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
 	"math/rand"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,36 +15,88 @@ import (
 	"github.com/dotzero/hooks/app/favicon"
 )
 
+const (
+	sortableWidth = 13 // base36 digits of max int64
+	secretSize    = 16
+)
+
+var (
+	// ErrInvalidName is returned for hook names out of the allowed charset or length
+	ErrInvalidName = errors.New("name must be 3-64 lowercase letters, digits, dots, dashes or underscores")
+	// ErrReservedName is returned for hook names clashing with the application routes or storage keys
+	ErrReservedName = errors.New("name is reserved")
+
+	nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,63}$`)
+
+	reservedNames = map[string]struct{}{
+		"api":         {},
+		"i":           {},
+		"static":      {},
+		"login":       {},
+		"robots.txt":  {},
+		"favicon.ico": {},
+		"hooks":       {}, // counter of hooks in storage
+	}
+)
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
 // Hook is a hook model
 type Hook struct {
-	Name     string     `json:"name"`
-	Secret   string     `json:"secret"`
-	Private  bool       `json:"private"`
-	Color    [4]uint8   `json:"color"`
-	Created  time.Time  `json:"time"`
-	Requests []*Request `json:"-"`
+	Name          string         `json:"name"`
+	Secret        string         `json:"secret"`
+	Private       bool           `json:"private"`
+	Color         [4]uint8       `json:"color"`
+	Created       time.Time      `json:"time"`
+	Expires       time.Time      `json:"expires"` // zero for owned hooks and hooks created before per-hook ttl
+	Owner         string         `json:"owner,omitempty"`
+	Response      *Response      `json:"response,omitempty"`
+	Forward       *Forward       `json:"forward,omitempty"`
+	Signature     *Signature     `json:"signature,omitempty"`
+	Notifications *Notifications `json:"notifications,omitempty"`
 }
 
 // NewHook returns a new hook model
 func NewHook(private bool) *Hook {
 	rgba := favicon.RandomRGBA()
 
-	hook := &Hook{
+	// the secret grants access to private hooks and changes of settings of any hook
+	return &Hook{
 		Name:    tinyID(),
+		Secret:  newSecret(),
+		Private: private,
 		Created: time.Now(),
 		Color:   [4]uint8{rgba.R, rgba.G, rgba.B, rgba.A},
 	}
+}
+
+// ExpiresAt returns time the hook expires at, maxAge applies to hooks without own expiry.
+// Owned hooks never expire, zero time is returned for them
+func (h *Hook) ExpiresAt(maxAge time.Duration) time.Time {
+	if h.Owner != "" {
+		return time.Time{}
+	}
 
-	if private {
-		hook.Secret = tinyID()
-		hook.Private = true
+	if h.Expires.IsZero() {
+		return h.Created.Add(maxAge)
 	}
 
-	return hook
+	return h.Expires
+}
+
+// ValidateName checks the name could be used as a hook name
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	if _, ok := reservedNames[name]; ok {
+		return ErrReservedName
+	}
+
+	return nil
 }
 
 func tinyID() string {
@@ -48,3 +105,23 @@ func tinyID() string {
 
 	return strings.ToLower(base36.EncodeBytes(b))
 }
+
+// newSecret returns a random hook secret, it is a credential so it is taken from crypto/rand.
+// A failing source of randomness leaves no way to make the hook, hence the panic
+func newSecret() string {
+	b := make([]byte, secretSize)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// sortableID returns an id which sorts lexically in order of time,
+// a random suffix keeps ids of the same nanosecond apart
+func sortableID(t time.Time) string {
+	ts := strconv.FormatInt(t.UnixNano(), 36)
+	suffix := strconv.FormatInt(rand.Int63n(36*36*36), 36) // nolint:gosec
+
+	return strings.Repeat("0", sortableWidth-len(ts)) + ts + strings.Repeat("0", 3-len(suffix)) + suffix
+}
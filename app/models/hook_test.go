@@ -1,7 +1,9 @@
 package models
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,4 +14,45 @@ func TestNewHook(t *testing.T) {
 	assert.NotEmpty(t, model.Name)
 	assert.True(t, model.Private)
 	assert.NotEmpty(t, model.Secret)
+
+	model = NewHook(false)
+	assert.False(t, model.Private)
+	assert.Len(t, model.Secret, 2*secretSize)
+	assert.NotEqual(t, NewHook(false).Secret, model.Secret)
+}
+
+func TestHookExpiresAt(t *testing.T) {
+	model := NewHook(false)
+	assert.Equal(t, model.Created.Add(time.Hour), model.ExpiresAt(time.Hour))
+
+	model.Expires = model.Created.Add(10 * time.Minute)
+	assert.Equal(t, model.Expires, model.ExpiresAt(time.Hour))
+
+	model.Owner = "user"
+	assert.True(t, model.ExpiresAt(time.Hour).IsZero())
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"stripe-staging", "github_prod", "abc", "v1.2", NewHook(false).Name} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+
+	for _, name := range []string{"", "ab", "-abc", "Stripe", "a b", "a/b", strings.Repeat("a", 65)} {
+		assert.ErrorIs(t, ValidateName(name), ErrInvalidName, name)
+	}
+
+	for _, name := range []string{"api", "static", "robots.txt", "favicon.ico", "hooks"} {
+		assert.ErrorIs(t, ValidateName(name), ErrReservedName, name)
+	}
+}
+
+func TestSortableID(t *testing.T) {
+	now := time.Now()
+
+	prev := sortableID(now.Add(-time.Nanosecond))
+	next := sortableID(now)
+
+	assert.Len(t, next, sortableWidth+3)
+	assert.Less(t, prev, next)
+	assert.Less(t, sortableID(time.Unix(0, 0)), sortableID(now))
 }
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+)
+
+// File is a file attachment of a multipart request
+type File struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Size        int64
+	Hash        string // hex encoded sha256
+	Truncated   bool   // the file extends past the stored part of the body, it could not be downloaded
+}
+
+// ErrFileNotFound is returned when request has no attachment with given index
+var ErrFileNotFound = errors.New("file is not found")
+
+// Attachment returns file metadata and content by its index in the request,
+// truncated files are not found
+func (r *Request) Attachment(index int) (*File, []byte, error) {
+	if index < 0 || index >= len(r.Files) || r.Files[index].Truncated {
+		return nil, nil, ErrFileNotFound
+	}
+
+	var (
+		data []byte
+		n    int
+	)
+
+	boundary := multipartBoundary(r.Headers.Get("Content-Type"))
+
+	err := walkMultipart(r.Body, boundary, func(part *multipart.Part, content []byte) bool {
+		if part.FileName() == "" {
+			return true
+		}
+
+		if n == index {
+			data = content
+			return false
+		}
+
+		n++
+
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if data == nil {
+		return nil, nil, ErrFileNotFound
+	}
+
+	return r.Files[index], data, nil
+}
+
+// parseMultipart parses form fields and file attachments of multipart body
+func parseMultipart(body []byte, boundary string) (url.Values, []*File) {
+	fields := url.Values{}
+	files := make([]*File, 0)
+
+	_ = walkMultipart(body, boundary, func(part *multipart.Part, content []byte) bool {
+		if part.FileName() == "" {
+			fields.Add(part.FormName(), string(content))
+			return true
+		}
+
+		hash := sha256.Sum256(content)
+
+		files = append(files, &File{
+			Field:       part.FormName(),
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Size:        int64(len(content)),
+			Hash:        hex.EncodeToString(hash[:]),
+		})
+
+		return true
+	})
+
+	return fields, files
+}
+
+// readMultipart reads multipart body storing up to maxBodySize bytes of it.
+// Files are hashed as they are read, so the ones extending past maxBodySize are recorded too
+func readMultipart(reader io.ReadCloser, boundary string, maxBodySize int64) ([]byte, int64, []*File) {
+	defer func() { _ = reader.Close() }()
+
+	stored := &limitedBuffer{max: maxBodySize}
+	tee := io.TeeReader(reader, stored)
+	files := make([]*File, 0)
+
+	mr := multipart.NewReader(tee, boundary)
+
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break // the end, truncated or malformed body
+		}
+
+		if part.FileName() == "" {
+			continue
+		}
+
+		hash := sha256.New()
+
+		size, err := io.Copy(hash, part)
+		if err != nil {
+			break
+		}
+
+		files = append(files, &File{
+			Field:       part.FormName(),
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Size:        size,
+			Hash:        hex.EncodeToString(hash.Sum(nil)),
+		})
+	}
+
+	_, _ = io.Copy(ioutil.Discard, tee)
+
+	return stored.buf.Bytes(), stored.size, files
+}
+
+// limitedBuffer keeps up to max bytes written and counts all of them
+type limitedBuffer struct {
+	buf  bytes.Buffer
+	max  int64
+	size int64
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if free := b.max - int64(b.buf.Len()); free > 0 {
+		if free > int64(len(p)) {
+			free = int64(len(p))
+		}
+
+		b.buf.Write(p[:free])
+	}
+
+	b.size += int64(len(p))
+
+	return len(p), nil
+}
+
+// walkMultipart calls fn for every complete part of multipart body until fn returns false
+func walkMultipart(body []byte, boundary string, fn func(part *multipart.Part, content []byte) bool) error {
+	if boundary == "" {
+		return errors.New("no multipart boundary")
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err // truncated or malformed body
+		}
+
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return err
+		}
+
+		if !fn(part, content) {
+			return nil
+		}
+	}
+}
+
+func multipartBoundary(contentType string) string {
+	d, params, err := mime.ParseMediaType(contentType)
+	if err != nil || d != "multipart/form-data" {
+		return ""
+	}
+
+	return params["boundary"]
+}
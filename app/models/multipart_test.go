@@ -0,0 +1,100 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestMultipart(t *testing.T) {
+	req := newMultipartRequest(t)
+
+	model := NewRequest(req, testMaxBodySize)
+	assert.Equal(t, url.Values{"foo": {"bar"}}, model.FormData)
+	assert.Len(t, model.Files, 1)
+	assert.Equal(t, &File{
+		Field:       "upload",
+		Filename:    "hello.txt",
+		ContentType: "text/plain",
+		Size:        11,
+		Hash:        "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+	}, model.Files[0])
+
+	file, data, err := model.Attachment(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello.txt", file.Filename)
+	assert.Equal(t, []byte("hello world"), data)
+
+	_, _, err = model.Attachment(1)
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func TestRequestMultipartTruncated(t *testing.T) {
+	req := newMultipartRequest(t)
+
+	model := NewRequest(req, 100)
+	assert.True(t, model.BodyTruncated)
+
+	_, _, err := model.Attachment(0)
+	assert.Error(t, err)
+
+	// the file past the stored part of the body is recorded by its full size and hash
+	content := strings.Repeat("x", 1000)
+	req = newMultipartRequestWith(t, content)
+
+	model = NewRequest(req, 300)
+	assert.True(t, model.BodyTruncated)
+	assert.Equal(t, url.Values{"foo": {"bar"}}, model.FormData)
+	require.Len(t, model.Files, 1)
+
+	hash := sha256.Sum256([]byte(content))
+	assert.Equal(t, &File{
+		Field:       "upload",
+		Filename:    "hello.txt",
+		ContentType: "text/plain",
+		Size:        1000,
+		Hash:        hex.EncodeToString(hash[:]),
+		Truncated:   true,
+	}, model.Files[0])
+
+	_, _, err = model.Attachment(0)
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func newMultipartRequest(t *testing.T) *http.Request {
+	return newMultipartRequestWith(t, "hello world")
+}
+
+func newMultipartRequestWith(t *testing.T, content string) *http.Request {
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+	assert.NoError(t, mw.WriteField("foo", "bar"))
+
+	header := make(map[string][]string)
+	header["Content-Disposition"] = []string{`form-data; name="upload"; filename="hello.txt"`}
+	header["Content-Type"] = []string{"text/plain"}
+
+	part, err := mw.CreatePart(header)
+	assert.NoError(t, err)
+
+	_, err = part.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, mw.Close())
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", &body)
+	assert.NoError(t, err)
+
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return req
+}
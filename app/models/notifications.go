@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+)
+
+const (
+	defaultNotificationsRate = 10
+	maxNotificationsRate     = 60
+	maxNotificationsEmails   = 5
+)
+
+// Notifications are sinks notified about every request to the hook
+type Notifications struct {
+	Webhook string   `json:"webhook,omitempty"` // url receiving JSON summary of the request
+	Slack   string   `json:"slack,omitempty"`   // Slack or Mattermost incoming webhook url
+	Email   []string `json:"email,omitempty"`   // recipients of emails sent via the configured SMTP server
+	Rate    int      `json:"rate"`              // max notifications per minute, zero means default
+}
+
+// Validate checks that at least one sink is set and sinks are valid
+func (n *Notifications) Validate() error {
+	if n.Webhook == "" && n.Slack == "" && len(n.Email) == 0 {
+		return errors.New("webhook, slack or email is required")
+	}
+
+	for name, u := range map[string]string{"webhook": n.Webhook, "slack": n.Slack} {
+		if u == "" {
+			continue
+		}
+
+		if p, err := url.Parse(u); err != nil || (p.Scheme != "http" && p.Scheme != "https") || p.Host == "" {
+			return fmt.Errorf("%s must be an absolute http or https url", name)
+		}
+	}
+
+	if len(n.Email) > maxNotificationsEmails {
+		return fmt.Errorf("email could have at most %d recipients", maxNotificationsEmails)
+	}
+
+	for _, addr := range n.Email {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("email %q is not a valid address", addr)
+		}
+	}
+
+	if n.Rate < 0 || n.Rate > maxNotificationsRate {
+		return fmt.Errorf("rate must be between 0 and %d", maxNotificationsRate)
+	}
+
+	return nil
+}
+
+// RateLimit returns max number of notifications per minute
+func (n *Notifications) RateLimit() int {
+	if n.Rate == 0 {
+		return defaultNotificationsRate
+	}
+
+	return n.Rate
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationsValidate(t *testing.T) {
+	n := &Notifications{Webhook: "https://example.com/events", Rate: 5}
+	assert.NoError(t, n.Validate())
+	assert.Equal(t, 5, n.RateLimit())
+
+	n = &Notifications{Slack: "https://hooks.slack.com/services/T0/B0/X", Email: []string{"John <john@example.com>"}}
+	assert.NoError(t, n.Validate())
+	assert.Equal(t, defaultNotificationsRate, n.RateLimit())
+
+	for _, n := range []*Notifications{
+		{},
+		{Webhook: "localhost:8080"},
+		{Slack: "ftp://example.com"},
+		{Email: []string{"john"}},
+		{Email: []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com", "f@example.com"}},
+		{Webhook: "http://localhost", Rate: 100},
+		{Webhook: "http://localhost", Rate: -1},
+	} {
+		assert.Error(t, n.Validate(), n)
+	}
+}
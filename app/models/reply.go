@@ -0,0 +1,63 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	maxReplays = 10
+)
+
+// Reply is a response of the target the captured request was replayed to
+type Reply struct {
+	URL           string
+	Status        int
+	Headers       http.Header
+	Body          []byte // stored part of the body, base64 encoded in JSON
+	BodySize      int64  // size of the original body
+	BodyTruncated bool
+	Latency       time.Duration
+	Error         string
+	Created       time.Time
+}
+
+// NewReply returns a reply model of the target response, the body is stored up to maxBodySize bytes
+func NewReply(url string, resp *http.Response, latency time.Duration, maxBodySize int64) *Reply {
+	body, size := parseBody(resp.Body, maxBodySize)
+
+	return &Reply{
+		URL:           url,
+		Status:        resp.StatusCode,
+		Headers:       resp.Header,
+		Body:          body,
+		BodySize:      size,
+		BodyTruncated: size > int64(len(body)),
+		Latency:       latency,
+		Created:       time.Now(),
+	}
+}
+
+// NewFailedReply returns a reply model of the target that could not be reached
+func NewFailedReply(url string, err error, latency time.Duration) *Reply {
+	return &Reply{
+		URL:     url,
+		Error:   err.Error(),
+		Latency: latency,
+		Created: time.Now(),
+	}
+}
+
+// IsBinary reports whether the body could not be displayed as a text
+func (r *Reply) IsBinary() bool {
+	return isBinary(r.Body)
+}
+
+// AddReplay appends reply to the request replays, only the latest ones are kept
+func (r *Request) AddReplay(reply *Reply) {
+	r.Replays = append(r.Replays, reply)
+
+	if len(r.Replays) > maxReplays {
+		r.Replays = r.Replays[len(r.Replays)-maxReplays:]
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"mime"
@@ -8,14 +10,11 @@ import (
 	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dotzero/hooks/app/network"
 )
 
-const (
-	maxBodySize = 1024 * 10
-)
-
 // Request is a hook request model
 type Request struct {
 	Name          string
@@ -23,46 +22,201 @@ type Request struct {
 	Method        string
 	Path          string
 	Query         string
-	Body          string
+	Body          []byte // stored part of the body, base64 encoded in JSON
+	BodySize      int64  // size of the original body
+	BodyTruncated bool
 	ContentType   string
 	ContentLength int64
-	Headers       map[string]string
-	FormData      map[string]string
-	QueryData     map[string]string
+	Headers       http.Header
+	FormData      url.Values
+	QueryData     url.Values
+	Files         []*File
+	Signature     *SignatureCheck // verification of the request signature when configured on the hook
+	Upstream      *Reply          // response of the upstream the request was forwarded to
+	Mock          *Reply          // mock response of the hook the request was answered with
+	Replays       []*Reply        // latest replays of the request, oldest first
 	Created       time.Time
 }
 
-// NewRequest returns a new request model
-func NewRequest(r *http.Request) *Request {
+// NewRequest returns a new request model, the body is stored up to maxBodySize bytes
+func NewRequest(r *http.Request, maxBodySize int64) *Request {
+	return NewRequestAt(r, maxBodySize, time.Now())
+}
+
+// NewRequestAt returns a new request model captured at the given time, e.g. an imported one
+func NewRequestAt(r *http.Request, maxBodySize int64, created time.Time) *Request {
+	var (
+		body  []byte
+		size  int64
+		files []*File
+	)
+
+	boundary := multipartBoundary(r.Header.Get("Content-Type"))
+	if boundary != "" && r.Body != nil {
+		body, size, files = readMultipart(r.Body, boundary, maxBodySize)
+	} else {
+		body, size = parseBody(r.Body, maxBodySize)
+	}
+
+	// parse the form from the stored body as the original one is already consumed
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
 	_ = r.ParseForm()
 
-	clientIP := network.ClientIP(r)
+	// the client address of imported requests is unknown unless kept by forwarding headers
+	var remoteAddr string
+	if ip := network.ClientIP(r); ip != nil {
+		remoteAddr = ip.String()
+	}
+
+	formData := parseFormData(r.PostForm)
+
+	if boundary != "" {
+		// fields and files are complete only in the stored part of the body, the rest of files are truncated
+		var complete []*File
+		if formData, complete = parseMultipart(body, boundary); files == nil {
+			files = complete
+		}
+
+		for i := len(complete); i < len(files); i++ {
+			files[i].Truncated = true
+		}
+	}
 
 	return &Request{
-		Name:          tinyID(),
-		RemoteAddr:    clientIP.String(),
+		Name:          sortableID(created),
+		RemoteAddr:    remoteAddr,
 		Method:        r.Method,
 		Path:          r.URL.Path,
 		Query:         r.URL.RawQuery,
-		Body:          parseBody(r.Body),
+		Body:          body,
+		BodySize:      size,
+		BodyTruncated: size > int64(len(body)),
 		ContentType:   parseContentType(r.Header),
 		ContentLength: r.ContentLength,
 		Headers:       parseHeaders(r.Header),
-		FormData:      parseFormData(r.PostForm),
+		FormData:      formData,
 		QueryData:     parseQueryData(r.URL.Query()),
-		Created:       time.Now(),
+		Files:         files,
+		Created:       created,
 	}
 }
 
-func parseBody(reader io.ReadCloser) string {
-	body, _ := ioutil.ReadAll(reader)
+// UnmarshalJSON decodes request model including legacy records.
+// Records stored before the body became binary-safe keep the body as a plain string,
+// and records stored before multi-valued fields keep a single string value per key
+func (r *Request) UnmarshalJSON(data []byte) error {
+	type plain Request
+
+	aux := struct {
+		*plain
+		Body      json.RawMessage
+		BodySize  *int64
+		Headers   json.RawMessage
+		FormData  json.RawMessage
+		QueryData json.RawMessage
+	}{
+		plain: (*plain)(r),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	headers, err := decodeValues(aux.Headers)
+	if err != nil {
+		return err
+	}
+
+	r.Headers = http.Header(headers)
+
+	if r.FormData, err = decodeValues(aux.FormData); err != nil {
+		return err
+	}
+
+	if r.QueryData, err = decodeValues(aux.QueryData); err != nil {
+		return err
+	}
+
+	if aux.BodySize != nil {
+		r.BodySize = *aux.BodySize
+	}
+
+	if len(aux.Body) == 0 {
+		return nil
+	}
+
+	if aux.BodySize == nil {
+		var body string
+		if err := json.Unmarshal(aux.Body, &body); err != nil {
+			return err
+		}
+
+		r.Body = []byte(body)
+		r.BodySize = int64(len(body))
+
+		return nil
+	}
+
+	return json.Unmarshal(aux.Body, &r.Body)
+}
+
+// IsBinary reports whether the body could not be displayed as a text
+func (r *Request) IsBinary() bool {
+	return isBinary(r.Body)
+}
+
+// Subpath returns the request path following the hook name
+func (r *Request) Subpath(hook string) string {
+	return strings.TrimPrefix(r.Path, "/"+hook)
+}
+
+// Rename moves the request path under the new name of the hook
+func (r *Request) Rename(hook string, newName string) {
+	subpath := r.Subpath(hook)
+	if subpath != r.Path && (subpath == "" || strings.HasPrefix(subpath, "/")) {
+		r.Path = "/" + newName + subpath
+	}
+}
+
+func isBinary(body []byte) bool {
+	return !utf8.Valid(body) || bytes.IndexByte(body, 0) != -1
+}
+
+func parseBody(reader io.ReadCloser, maxBodySize int64) ([]byte, int64) {
+	if reader == nil {
+		return []byte{}, 0
+	}
+
 	defer func() { _ = reader.Close() }()
 
-	if len(body) > maxBodySize {
-		return string(body[0:maxBodySize])
+	body, _ := ioutil.ReadAll(io.LimitReader(reader, maxBodySize))
+	rest, _ := io.Copy(ioutil.Discard, reader)
+
+	return body, int64(len(body)) + rest
+}
+
+// decodeValues decodes multi-valued map, falling back to the legacy single-valued one
+func decodeValues(data json.RawMessage) (map[string][]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var values map[string][]string
+	if err := json.Unmarshal(data, &values); err == nil {
+		return values, nil
+	}
+
+	var legacy map[string]string
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, err
+	}
+
+	values = make(map[string][]string, len(legacy))
+	for name, value := range legacy {
+		values[name] = []string{value}
 	}
 
-	return string(body)
+	return values, nil
 }
 
 func parseContentType(headers http.Header) string {
@@ -76,7 +230,7 @@ func parseContentType(headers http.Header) string {
 	return contentType
 }
 
-func parseHeaders(headers http.Header) map[string]string {
+func parseHeaders(headers http.Header) http.Header {
 	ignore := map[string]struct{}{
 		"x-varnish":                {},
 		"x-forwarded-for":          {},
@@ -90,32 +244,32 @@ func parseHeaders(headers http.Header) map[string]string {
 		"x-forwarded-port":         {},
 	}
 
-	parsed := make(map[string]string, len(headers))
+	parsed := make(http.Header, len(headers))
 
-	for name, value := range headers {
+	for name, values := range headers {
 		if _, ok := ignore[strings.ToLower(name)]; !ok {
-			parsed[name] = value[0]
+			parsed[name] = append([]string(nil), values...)
 		}
 	}
 
 	return parsed
 }
 
-func parseFormData(form url.Values) map[string]string {
-	parsed := make(map[string]string, len(form))
+func parseFormData(form url.Values) url.Values {
+	parsed := make(url.Values, len(form))
 
-	for name, value := range form {
-		parsed[name] = value[0]
+	for name, values := range form {
+		parsed[name] = append([]string(nil), values...)
 	}
 
 	return parsed
 }
 
-func parseQueryData(query url.Values) map[string]string {
-	parsed := make(map[string]string, len(query))
+func parseQueryData(query url.Values) url.Values {
+	parsed := make(url.Values, len(query))
 
-	for name, value := range query {
-		parsed[name] = value[0]
+	for name, values := range query {
+		parsed[name] = append([]string(nil), values...)
 	}
 
 	return parsed
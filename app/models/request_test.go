@@ -3,6 +3,7 @@ package models
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"strings"
@@ -11,13 +12,50 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+const (
+	testMaxBodySize = 1024 * 10
+)
+
 func TestRequestHugeBody(t *testing.T) {
 	body := strings.Repeat(`{}`, 2048*10)
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", bytes.NewBufferString(body))
 	assert.NoError(t, err)
 
-	model := NewRequest(req)
-	assert.Len(t, model.Body, maxBodySize)
+	model := NewRequest(req, testMaxBodySize)
+	assert.Len(t, model.Body, testMaxBodySize)
+	assert.Equal(t, int64(len(body)), model.BodySize)
+	assert.True(t, model.BodyTruncated)
+}
+
+func TestRequestBinaryBody(t *testing.T) {
+	body := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	model := NewRequest(req, testMaxBodySize)
+	assert.Equal(t, body, model.Body)
+	assert.Equal(t, int64(len(body)), model.BodySize)
+	assert.False(t, model.BodyTruncated)
+	assert.True(t, model.IsBinary())
+
+	data, err := json.Marshal(model)
+	assert.NoError(t, err)
+
+	var act Request
+	assert.NoError(t, json.Unmarshal(data, &act))
+	assert.Equal(t, body, act.Body)
+	assert.Equal(t, model.BodySize, act.BodySize)
+}
+
+func TestRequestLegacyBody(t *testing.T) {
+	var act Request
+
+	err := json.Unmarshal([]byte(`{"Name":"foo","Body":"{\"foo\": \"bar\"}","ContentLength":14}`), &act)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", act.Name)
+	assert.Equal(t, `{"foo": "bar"}`, string(act.Body))
+	assert.Equal(t, int64(14), act.BodySize)
+	assert.False(t, act.IsBinary())
 }
 
 func TestRequestContentTypeBoundary(t *testing.T) {
@@ -26,7 +64,7 @@ func TestRequestContentTypeBoundary(t *testing.T) {
 
 	req.Header.Add("Content-Type", `multipart/form-data;boundary="boundary"`)
 
-	model := NewRequest(req)
+	model := NewRequest(req, testMaxBodySize)
 	assert.Equal(t, "multipart/form-data", model.ContentType)
 }
 
@@ -45,7 +83,7 @@ func TestRequestStripHeaders(t *testing.T) {
 	req.Header.Add("X-Amzn-Trace-Id", "Root=1-6268e794-11cd66b95ec7e46b039ab76e")
 	req.Header.Add("X-Forwarded-For", "0.0.0.0") // ignore
 
-	model := NewRequest(req)
+	model := NewRequest(req, testMaxBodySize)
 	assert.Len(t, model.Headers, 8)
 }
 
@@ -62,8 +100,9 @@ func TestRequestPost(t *testing.T) {
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	model := NewRequest(req)
+	model := NewRequest(req, testMaxBodySize)
 	assert.Len(t, model.FormData, 2)
+	assert.Equal(t, []string{"value1", "value2"}, model.FormData["params[]"])
 }
 
 func TestRequestGet(t *testing.T) {
@@ -77,6 +116,53 @@ func TestRequestGet(t *testing.T) {
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, bytes.NewBufferString(""))
 	assert.NoError(t, err)
 
-	model := NewRequest(req)
+	model := NewRequest(req, testMaxBodySize)
 	assert.Len(t, model.QueryData, 2)
+	assert.Equal(t, []string{"value1", "value2"}, model.QueryData["params[]"])
+}
+
+func TestRequestMultiValuedHeaders(t *testing.T) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", bytes.NewBufferString(""))
+	assert.NoError(t, err)
+
+	req.Header.Add("Set-Cookie", "a=1")
+	req.Header.Add("Set-Cookie", "b=2")
+
+	model := NewRequest(req, testMaxBodySize)
+	assert.Equal(t, []string{"a=1", "b=2"}, model.Headers["Set-Cookie"])
+
+	data, err := json.Marshal(model)
+	assert.NoError(t, err)
+
+	var act Request
+	assert.NoError(t, json.Unmarshal(data, &act))
+	assert.Equal(t, model.Headers, act.Headers)
+}
+
+func TestRequestLegacyValues(t *testing.T) {
+	var act Request
+
+	err := json.Unmarshal([]byte(`{
+		"Name": "foo",
+		"Headers": {"Content-Type": "application/x-www-form-urlencoded"},
+		"FormData": {"param": "value"},
+		"QueryData": {"single": ""}
+	}`), &act)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/x-www-form-urlencoded", act.Headers.Get("Content-Type"))
+	assert.Equal(t, url.Values{"param": {"value"}}, act.FormData)
+	assert.Equal(t, url.Values{"single": {""}}, act.QueryData)
+}
+
+func TestRequestRename(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/old":         "/new",
+		"/old/orders":  "/new/orders",
+		"/older/items": "/older/items",
+		"/elsewhere":   "/elsewhere",
+	} {
+		req := &Request{Path: path}
+		req.Rename("old", "new")
+		assert.Equal(t, expected, req.Path, path)
+	}
 }
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+const (
+	maxResponseDelay = 30 * time.Second
+)
+
+// Response is a mock response that a hook returns to the sender
+type Response struct {
+	Status      int               `json:"status"`
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers"`
+	Body        string            `json:"body"`
+	Delay       int               `json:"delay"` // milliseconds
+}
+
+// Validate checks that response could be rendered
+func (r *Response) Validate() error {
+	if r.Status < 200 || r.Status > 599 {
+		return errors.New("status code must be between 200 and 599")
+	}
+
+	if r.Delay < 0 || r.DelayDuration() > maxResponseDelay {
+		return fmt.Errorf("delay must be between 0 and %d ms", maxResponseDelay.Milliseconds())
+	}
+
+	if _, err := template.New("body").Parse(r.Body); err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+
+	return nil
+}
+
+// DelayDuration returns delay before the response as duration
+func (r *Response) DelayDuration() time.Duration {
+	return time.Duration(r.Delay) * time.Millisecond
+}
+
+// Render executes body template against captured request
+func (r *Response) Render(req *Request) ([]byte, error) {
+	tmpl, err := template.New("body").Parse(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, req); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Reply renders the mock response of the captured request, a failed rendering is kept as the reply error
+func (r *Response) Reply(req *Request) *Reply {
+	body, err := r.Render(req)
+	if err != nil {
+		return NewFailedReply("", err, 0)
+	}
+
+	headers := http.Header{}
+	for name, value := range r.Headers {
+		headers.Set(name, value)
+	}
+
+	if r.ContentType != "" {
+		headers.Set("Content-Type", r.ContentType)
+	}
+
+	return &Reply{
+		Status:   r.Status,
+		Headers:  headers,
+		Body:     body,
+		BodySize: int64(len(body)),
+		Latency:  r.DelayDuration(),
+		Created:  time.Now(),
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseValidate(t *testing.T) {
+	resp := &Response{Status: 201, Body: `{"method": "{{ .Method }}"}`, Delay: 100}
+	assert.NoError(t, resp.Validate())
+
+	resp = &Response{Status: 42}
+	assert.Error(t, resp.Validate())
+
+	resp = &Response{Status: 101}
+	assert.Error(t, resp.Validate())
+
+	resp = &Response{Status: 200, Delay: 60000}
+	assert.Error(t, resp.Validate())
+
+	resp = &Response{Status: 200, Body: "{{ .Method "}
+	assert.Error(t, resp.Validate())
+}
+
+func TestResponseRender(t *testing.T) {
+	resp := &Response{Status: 200, Body: `{"method": "{{ .Method }}", "path": "{{ .Path }}"}`}
+
+	body, err := resp.Render(&Request{Method: "POST", Path: "/foo/bar"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"method": "POST", "path": "/foo/bar"}`, string(body))
+}
+
+func TestResponseReply(t *testing.T) {
+	resp := &Response{Status: 201, ContentType: "text/plain", Headers: map[string]string{"x-foo": "bar"}, Body: "{{ .Method }}", Delay: 20}
+
+	reply := resp.Reply(&Request{Method: "POST"})
+	assert.Equal(t, 201, reply.Status)
+	assert.Equal(t, "text/plain", reply.Headers.Get("Content-Type"))
+	assert.Equal(t, "bar", reply.Headers.Get("X-Foo"))
+	assert.Equal(t, "POST", string(reply.Body))
+	assert.Equal(t, int64(4), reply.BodySize)
+	assert.Equal(t, 20*time.Millisecond, reply.Latency)
+	assert.Empty(t, reply.Error)
+
+	reply = (&Response{Status: 200, Body: "{{ .Missing }}"}).Reply(&Request{})
+	assert.NotEmpty(t, reply.Error)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"time"
+)
+
+// Signature providers
+const (
+	ProviderGitHub = "github"
+	ProviderStripe = "stripe"
+	ProviderSlack  = "slack"
+	ProviderHMAC   = "hmac"
+)
+
+const (
+	defaultSignatureHeader    = "X-Signature"
+	defaultSignatureTolerance = 5 * time.Minute
+	maxSignatureTolerance     = 24 * time.Hour
+)
+
+// Signature is a configuration of signature verification of incoming requests
+type Signature struct {
+	Provider  string `json:"provider"`
+	Secret    string `json:"secret,omitempty"`     // stored with the hook, it is never shown by Masked copies
+	SecretSet bool   `json:"secret_set,omitempty"` // set by Masked instead of the secret
+	Header    string `json:"header,omitempty"`     // signature header of the generic hmac provider
+	Tolerance int    `json:"tolerance,omitempty"`  // seconds, zero means default
+}
+
+// Masked returns a copy of the configuration safe to show to clients
+func (s *Signature) Masked() *Signature {
+	if s == nil {
+		return nil
+	}
+
+	masked := *s
+	masked.SecretSet = s.Secret != ""
+	masked.Secret = ""
+
+	return &masked
+}
+
+// Validate checks that the configuration could be used to verify requests
+func (s *Signature) Validate() error {
+	switch s.Provider {
+	case ProviderGitHub, ProviderStripe, ProviderSlack, ProviderHMAC:
+	default:
+		return fmt.Errorf("provider must be one of %s, %s, %s or %s",
+			ProviderGitHub, ProviderStripe, ProviderSlack, ProviderHMAC)
+	}
+
+	if s.Secret == "" {
+		return errors.New("secret must not be empty")
+	}
+
+	if s.Tolerance < 0 || s.ToleranceDuration() > maxSignatureTolerance {
+		return fmt.Errorf("tolerance must be between 0 and %d seconds", int(maxSignatureTolerance.Seconds()))
+	}
+
+	return nil
+}
+
+// HeaderName returns the canonical name of the signature header of the generic hmac provider
+func (s *Signature) HeaderName() string {
+	if s.Header == "" {
+		return defaultSignatureHeader
+	}
+
+	return textproto.CanonicalMIMEHeaderKey(s.Header)
+}
+
+// ToleranceDuration returns the allowed age of signed timestamps as duration
+func (s *Signature) ToleranceDuration() time.Duration {
+	if s.Tolerance == 0 {
+		return defaultSignatureTolerance
+	}
+
+	return time.Duration(s.Tolerance) * time.Second
+}
+
+// SignatureCheck is a result of the request signature verification
+type SignatureCheck struct {
+	Provider string
+	Valid    bool
+	Reason   string // why the signature is not valid
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureValidate(t *testing.T) {
+	s := &Signature{Provider: ProviderStripe, Secret: "whsec", Tolerance: 60}
+	assert.NoError(t, s.Validate())
+	assert.Equal(t, time.Minute, s.ToleranceDuration())
+
+	s = &Signature{Provider: ProviderHMAC, Secret: "secret"}
+	assert.NoError(t, s.Validate())
+	assert.Equal(t, defaultSignatureTolerance, s.ToleranceDuration())
+	assert.Equal(t, "X-Signature", s.HeaderName())
+
+	s.Header = "x-hmac-sha256"
+	assert.Equal(t, "X-Hmac-Sha256", s.HeaderName())
+
+	s = &Signature{Provider: "paypal", Secret: "secret"}
+	assert.Error(t, s.Validate())
+
+	s = &Signature{Provider: ProviderGitHub}
+	assert.Error(t, s.Validate())
+
+	s = &Signature{Provider: ProviderSlack, Secret: "secret", Tolerance: -1}
+	assert.Error(t, s.Validate())
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLen = 8
+	maxPasswordLen = 72 // bcrypt ignores the rest
+)
+
+// dummyPassword is a bcrypt hash of the default cost compared for unknown users
+var dummyPassword = []byte("$2a$10$364s/t55TVFYYLzWAzXLN.id/r5SdZSIJA.10BAScX.WoysJThcI2")
+
+var (
+	// ErrInvalidUsername is returned for usernames out of the allowed charset or length
+	ErrInvalidUsername = errors.New("username must be 3-32 lowercase letters, digits, dashes or underscores")
+	// ErrInvalidPassword is returned for too short or too long passwords
+	ErrInvalidPassword = fmt.Errorf("password must be %d-%d characters long", minPasswordLen, maxPasswordLen)
+
+	usernameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{2,31}$`)
+)
+
+// User is an account owning hooks, it could be shared by a team
+type User struct {
+	Name     string    `json:"name"`
+	Password []byte    `json:"password"` // bcrypt hash
+	Created  time.Time `json:"time"`
+}
+
+// NewUser returns a new user model with the hashed password
+func NewUser(name string, password string) (*User, error) {
+	if !usernameRe.MatchString(name) {
+		return nil, ErrInvalidUsername
+	}
+
+	if len(password) < minPasswordLen || len(password) > maxPasswordLen {
+		return nil, ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{
+		Name:     name,
+		Password: hash,
+		Created:  time.Now(),
+	}, nil
+}
+
+// CheckPassword reports whether the password matches the user one, it is false for nil users
+func (u *User) CheckPassword(password string) bool {
+	if u == nil {
+		// unknown users take the same time to check, so the response does not reveal existing usernames
+		_ = bcrypt.CompareHashAndPassword(dummyPassword, []byte(password))
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword(u.Password, []byte(password)) == nil
+}
+
+// Session is a signed in user session
+type Session struct {
+	ID      string    `json:"id"`
+	User    string    `json:"user"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewSession returns a new session of the user with a random id
+func NewSession(user string, ttl time.Duration) (*Session, error) {
+	b := make([]byte, 32)
+	if _, err := crand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		ID:      hex.EncodeToString(b),
+		User:    user,
+		Expires: time.Now().Add(ttl),
+	}, nil
+}
+
+// Expired reports whether the session is expired
+func (s *Session) Expired() bool {
+	return !s.Expires.After(time.Now())
+}
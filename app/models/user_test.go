@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestNewUser(t *testing.T) {
+	user, err := NewUser("team-a", "password")
+	require.NoError(t, err)
+
+	assert.Equal(t, "team-a", user.Name)
+	assert.NotEqual(t, []byte("password"), user.Password)
+	assert.True(t, user.CheckPassword("password"))
+	assert.False(t, user.CheckPassword("Password"))
+
+	_, err = NewUser("Team A", "password")
+	assert.ErrorIs(t, err, ErrInvalidUsername)
+
+	_, err = NewUser("team", "short")
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+
+	_, err = NewUser("team", strings.Repeat("a", 73))
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+}
+
+func TestCheckPasswordUnknownUser(t *testing.T) {
+	var user *User
+	assert.False(t, user.CheckPassword("password"))
+
+	// the dummy hash costs as much as hashes of users
+	cost, err := bcrypt.Cost(dummyPassword)
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestNewSession(t *testing.T) {
+	session, err := NewSession("team", time.Hour)
+	require.NoError(t, err)
+
+	assert.Len(t, session.ID, 64)
+	assert.Equal(t, "team", session.User)
+	assert.False(t, session.Expired())
+
+	other, err := NewSession("team", time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, session.ID, other.ID)
+
+	session.Expires = time.Now().Add(-time.Second)
+	assert.True(t, session.Expired())
+}
@@ -40,11 +40,38 @@ func (a *App) routes() *chi.Mux {
 	router.Use(middleware.RedirectSlashes)
 	router.Use(corsMiddleware.Handler)
 
-	router.Get("/", handlers.WebHome(a.Storage, a.Templates.Lookup("home.html"), a.AppURL, a.BoltTTL))
-	router.Get("/i/{hook}", handlers.WebInspect(a.Storage, a.Templates.Lookup("hook.html"), a.AppURL, a.BoltTTL))
-	router.Post("/api/create", handlers.APICreate(a.Storage))
+	router.Get("/", handlers.WebHome(a.Storage, a.Templates.Lookup("home.html"), a.AppURL, a.BoltTTL, a.MaxTTL))
+	router.Get("/i/{hook}", handlers.WebInspect(a.Storage, a.Templates.Lookup("hook.html"), a.AppURL, a.BoltTTL, a.MaxTTL))
+	router.Get("/login", handlers.WebLogin(a.Storage, a.Templates.Lookup("login.html"), a.AppURL))
+	router.Get("/i/{hook}/stream", handlers.WebStream(a.Storage, a.Broker, a.Templates.Lookup("partials/request.html"), a.AppURL))
+	router.Post("/api/create", handlers.APICreate(a.Storage, time.Duration(a.BoltTTL)*time.Hour, time.Duration(a.MaxTTL)*time.Hour))
 	router.Get("/api/stats", handlers.APIStats(a.Storage, a.BoltTTL))
-	router.Handle("/{hook}", handlers.APIHook(a.Storage))
+	router.Post("/api/users", handlers.APISignUp(a.Storage))
+	router.Post("/api/login", handlers.APISignIn(a.Storage))
+	router.Post("/api/logout", handlers.APISignOut(a.Storage))
+	router.Get("/api/user/hooks", handlers.APIUserHooks(a.Storage))
+	router.Get("/api/hooks/{hook}", handlers.APIGetHook(a.Storage))
+	router.Delete("/api/hooks/{hook}", handlers.APIDeleteHook(a.Storage))
+	router.Post("/api/hooks/{hook}/rename", handlers.APIRenameHook(a.Storage))
+	router.Get("/api/hooks/{hook}/requests", handlers.APIRequests(a.Storage))
+	router.Get("/api/hooks/{hook}/har", handlers.APIExportHAR(a.Storage, a.AppURL, a.Version))
+	router.Post("/api/hooks/{hook}/import", handlers.APIImport(a.Storage, a.MaxBodySize))
+	router.Get("/api/hooks/{hook}/requests/next", handlers.APINextRequest(a.Storage, a.Broker))
+	router.Get("/api/hooks/{hook}/requests/{request}", handlers.APIRequest(a.Storage))
+	router.Get("/api/hooks/{hook}/requests/{request}/body", handlers.APIRequestBody(a.Storage))
+	router.Get("/api/hooks/{hook}/requests/{request}/snippets", handlers.APIRequestSnippets(a.Storage, a.AppURL))
+	router.Get("/api/hooks/{hook}/requests/{request}/files/{file}", handlers.APIRequestFile(a.Storage))
+	router.Post("/api/hooks/{hook}/requests/{request}/replay", handlers.APIReplayRequest(a.Storage, a.Proxy))
+	router.Post("/api/hooks/{hook}/response", handlers.APIUpdateResponse(a.Storage))
+	router.Delete("/api/hooks/{hook}/response", handlers.APIDeleteResponse(a.Storage))
+	router.Post("/api/hooks/{hook}/forward", handlers.APIUpdateForward(a.Storage))
+	router.Delete("/api/hooks/{hook}/forward", handlers.APIDeleteForward(a.Storage))
+	router.Post("/api/hooks/{hook}/signature", handlers.APIUpdateSignature(a.Storage))
+	router.Delete("/api/hooks/{hook}/signature", handlers.APIDeleteSignature(a.Storage))
+	router.Post("/api/hooks/{hook}/notifications", handlers.APIUpdateNotifications(a.Storage, a.Notifier))
+	router.Delete("/api/hooks/{hook}/notifications", handlers.APIDeleteNotifications(a.Storage))
+	router.Handle("/{hook}", handlers.APIHook(a.Storage, a.Broker, a.Proxy, a.Notifier, a.MaxBodySize))
+	router.Handle("/{hook}/*", handlers.APIHook(a.Storage, a.Broker, a.Proxy, a.Notifier, a.MaxBodySize))
 
 	router.Get("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
 		render.PlainText(w, r, "User-agent: *\n")
@@ -0,0 +1,63 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when dialing a loopback, private, link-local or unspecified address
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// PublicAddress reports whether the ip is routable in the public network
+func PublicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// publicOnly is the dialer control refusing connections to non-public addresses.
+// It runs after the name resolution, so the check could not be bypassed by DNS records
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !PublicAddress(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// Dialer returns a dialer of outgoing connections to user supplied urls,
+// connections to the local network are refused unless allowPrivate is set
+func Dialer(timeout time.Duration, allowPrivate bool) *net.Dialer {
+	d := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		d.Control = publicOnly
+	}
+
+	return d
+}
+
+// Transport returns a transport dialing by the Dialer.
+// Environment proxies are not used as the address check would apply to the proxy
+func Transport(timeout time.Duration, allowPrivate bool) *http.Transport {
+	return &http.Transport{
+		DialContext:           Dialer(timeout, allowPrivate).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
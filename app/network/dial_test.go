@@ -0,0 +1,50 @@
+package network
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicAddress(t *testing.T) {
+	for _, addr := range []string{"1.1.1.1", "93.184.216.34", "2606:2800:220:1::"} {
+		assert.True(t, PublicAddress(net.ParseIP(addr)), addr)
+	}
+
+	for _, addr := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "224.0.0.1", "::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1",
+	} {
+		assert.False(t, PublicAddress(net.ParseIP(addr)), addr)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	get := func(allowPrivate bool) error {
+		client := &http.Client{Transport: Transport(time.Second, allowPrivate)}
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	assert.ErrorIs(t, get(false), ErrForbiddenAddress)
+
+	assert.NoError(t, get(true))
+}
@@ -0,0 +1,530 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/network"
+)
+
+const (
+	queueSize   = 1000
+	workers     = 4
+	maxAttempts = 3
+	rateWindow  = time.Minute
+	sendTimeout = 10 * time.Second
+)
+
+// ErrNotConfigured is returned when sending emails without SMTP server
+var ErrNotConfigured = errors.New("smtp server is not configured")
+
+// slackEscaper escapes control characters of Slack message formatting
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SMTP is the server sending email notifications
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Summary is a short description of the captured request sent to the sinks
+type Summary struct {
+	Hook        string    `json:"hook"`
+	Request     string    `json:"request"`
+	URL         string    `json:"url"` // link to the request in the hook inspector
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	Query       string    `json:"query,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	BodySize    int64     `json:"body_size"`
+	RemoteAddr  string    `json:"remote_addr,omitempty"`
+	Signature   *bool     `json:"signature_valid,omitempty"`
+	Suppressed  int       `json:"suppressed,omitempty"` // notifications dropped by the rate limit since the previous one
+	Created     time.Time `json:"time"`
+}
+
+// job is a delivery of the summary to a single sink
+type job struct {
+	sink    string
+	summary *Summary
+	send    func(ctx context.Context, s *Summary) error
+}
+
+// window counts notifications of the hook within the rate window
+type window struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// Notifier delivers notifications asynchronously by a pool of workers.
+// Deliveries are retried with exponential backoff, a full queue drops new notifications
+type Notifier struct {
+	baseURL string
+	smtp    SMTP
+	client  *http.Client
+	backoff time.Duration
+	queue   chan *job
+
+	mu      sync.Mutex
+	windows map[string]*window
+
+	now      func() time.Time
+	sendMail func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// New returns a notifier linking requests to the hook inspector at baseURL.
+// Webhooks in the local network are refused unless allowPrivate is set
+func New(baseURL string, smtpServer SMTP, allowPrivate bool) *Notifier {
+	n := &Notifier{
+		baseURL: baseURL,
+		smtp:    smtpServer,
+		client: &http.Client{
+			Transport: network.Transport(sendTimeout, allowPrivate),
+			Timeout:   sendTimeout,
+		},
+		backoff: time.Second,
+		queue:   make(chan *job, queueSize),
+		windows: make(map[string]*window),
+		now:     time.Now,
+	}
+
+	n.sendMail = n.send
+
+	return n
+}
+
+// EmailEnabled reports whether the SMTP server is configured
+func (n *Notifier) EmailEnabled() bool {
+	return n.smtp.Host != "" && n.smtp.From != ""
+}
+
+// Notify queues notifications of the request to the hook sinks.
+// It never blocks, notifications over the hook rate limit or the queue size are dropped
+func (n *Notifier) Notify(hook *models.Hook, req *models.Request) {
+	cfg := hook.Notifications
+	if cfg == nil {
+		return
+	}
+
+	suppressed, ok := n.allow(hook.Name, cfg.RateLimit())
+	if !ok {
+		return
+	}
+
+	summary := n.summary(hook.Name, req)
+	summary.Suppressed = suppressed
+
+	var jobs []*job
+
+	if cfg.Webhook != "" {
+		jobs = append(jobs, &job{"webhook", summary, func(ctx context.Context, s *Summary) error {
+			return n.post(ctx, cfg.Webhook, s)
+		}})
+	}
+
+	if cfg.Slack != "" {
+		jobs = append(jobs, &job{"slack", summary, func(ctx context.Context, s *Summary) error {
+			return n.post(ctx, cfg.Slack, map[string]string{"text": slackText(s)})
+		}})
+	}
+
+	if len(cfg.Email) > 0 {
+		jobs = append(jobs, &job{"email", summary, func(ctx context.Context, s *Summary) error {
+			return n.email(ctx, cfg.Email, s)
+		}})
+	}
+
+	for _, j := range jobs {
+		select {
+		case n.queue <- j:
+		default:
+			log.Printf("[WARN] notification queue is full, %s notification of hook %s is dropped", j.sink, hook.Name)
+		}
+	}
+}
+
+// Run delivers queued notifications until the context is done, pending ones are dropped
+func (n *Notifier) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case j := <-n.queue:
+					n.deliver(ctx, j)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(rateWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n.prune()
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+	}
+}
+
+// allow counts the notification in the hook rate window,
+// it returns the number of notifications suppressed in the previous windows
+func (n *Notifier) allow(hook string, limit int) (int, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := n.now()
+
+	w := n.windows[hook]
+	if w == nil {
+		w = &window{start: now}
+		n.windows[hook] = w
+	}
+
+	if now.Sub(w.start) >= rateWindow {
+		w.start, w.count = now, 0
+	}
+
+	if w.count >= limit {
+		if w.suppressed == 0 {
+			log.Printf("[WARN] notifications of hook %s exceed %d per minute and are suppressed", hook, limit)
+		}
+
+		w.suppressed++
+
+		return 0, false
+	}
+
+	w.count++
+
+	suppressed := w.suppressed
+	w.suppressed = 0
+
+	return suppressed, true
+}
+
+// prune forgets windows of hooks without recent notifications
+func (n *Notifier) prune() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := n.now()
+
+	for hook, w := range n.windows {
+		if now.Sub(w.start) >= rateWindow && w.suppressed == 0 {
+			delete(n.windows, hook)
+		}
+	}
+}
+
+// deliver sends the notification retrying failures with exponential backoff
+func (n *Notifier) deliver(ctx context.Context, j *job) {
+	delay := n.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := j.send(ctx, j.summary)
+		if err == nil {
+			return
+		}
+
+		var perm permanentError
+		if attempt == maxAttempts || errors.As(err, &perm) {
+			log.Printf("[WARN] failed to send %s notification of hook %s, %s", j.sink, j.summary.Hook, err)
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// permanentError is a failure that is not retried
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// post sends the payload as JSON, client errors except 429 are not retried
+func (n *Notifier) post(ctx context.Context, u string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return permanentError{err}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return permanentError{err}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hooks")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		if errors.Is(err, network.ErrForbiddenAddress) {
+			return permanentError{err}
+		}
+
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return permanentError{fmt.Errorf("unexpected status %s", resp.Status)}
+	default:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// email sends the plain text summary to the recipients
+func (n *Notifier) email(ctx context.Context, to []string, s *Summary) error {
+	if !n.EmailEnabled() {
+		return permanentError{ErrNotConfigured}
+	}
+
+	// the envelope takes bare addresses while headers keep the names
+	from, err := mail.ParseAddress(n.smtp.From)
+	if err != nil {
+		return permanentError{err}
+	}
+
+	rcpt := make([]string, 0, len(to))
+	headerTo := make([]string, 0, len(to))
+
+	for _, s := range to {
+		a, err := mail.ParseAddress(s)
+		if err != nil {
+			return permanentError{err}
+		}
+
+		rcpt = append(rcpt, a.Address)
+		headerTo = append(headerTo, a.String())
+	}
+
+	subject := fmt.Sprintf("[hooks] %s %s", s.Method, s.Path)
+
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(headerTo, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject)) // the path could contain line breaks
+	fmt.Fprintf(&msg, "Date: %s\r\n", s.Created.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text(s), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if n.smtp.Username != "" {
+		auth = smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, n.smtp.Host)
+	}
+
+	port := n.smtp.Port
+	if port == 0 {
+		port = 587
+	}
+
+	addr := net.JoinHostPort(n.smtp.Host, strconv.Itoa(port))
+
+	return n.sendMail(ctx, addr, auth, from.Address, rcpt, msg.Bytes())
+}
+
+// send delivers the message via the SMTP server upgrading the connection by STARTTLS when supported.
+// The exchange is limited by sendTimeout and aborted when the context is done, e.g. on shutdown
+func (n *Notifier) send(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	d := net.Dialer{Timeout: sendTimeout}
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close() // unblocks the exchange
+		case <-done:
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, n.smtp.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	defer func() { _ = c.Close() }()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.smtp.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return permanentError{errors.New("smtp server does not support authentication")}
+		}
+
+		if err := c.Auth(a); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	wc, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := wc.Write(msg); err != nil {
+		return err
+	}
+
+	if err := wc.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (n *Notifier) summary(hook string, req *models.Request) *Summary {
+	s := &Summary{
+		Hook:        hook,
+		Request:     req.Name,
+		URL:         fmt.Sprintf("%s/i/%s#%s", n.baseURL, hook, req.Name),
+		Method:      req.Method,
+		Path:        req.Path,
+		Query:       req.Query,
+		ContentType: req.ContentType,
+		BodySize:    req.BodySize,
+		RemoteAddr:  req.RemoteAddr,
+		Created:     req.Created,
+	}
+
+	if req.Signature != nil {
+		valid := req.Signature.Valid
+		s.Signature = &valid
+	}
+
+	return s
+}
+
+// text returns the human readable summary
+func text(s *Summary) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Hook %s received %s %s", s.Hook, s.Method, s.Path)
+
+	if s.Query != "" {
+		b.WriteString("?" + s.Query)
+	}
+
+	b.WriteString("\n")
+
+	if s.ContentType != "" {
+		fmt.Fprintf(&b, "Body: %s, %s\n", s.ContentType, humanize.Bytes(uint64(s.BodySize)))
+	}
+
+	if s.RemoteAddr != "" {
+		fmt.Fprintf(&b, "From: %s\n", s.RemoteAddr)
+	}
+
+	if s.Signature != nil {
+		if *s.Signature {
+			b.WriteString("Signature: valid\n")
+		} else {
+			b.WriteString("Signature: invalid\n")
+		}
+	}
+
+	if s.Suppressed > 0 {
+		fmt.Fprintf(&b, "%d previous notifications were suppressed by the rate limit\n", s.Suppressed)
+	}
+
+	b.WriteString(s.URL + "\n")
+
+	return b.String()
+}
+
+// slackText returns the summary formatted for Slack and Mattermost
+func slackText(s *Summary) string {
+	target := s.Path
+	if s.Query != "" {
+		target += "?" + s.Query
+	}
+
+	// request values must not turn into mentions or links of Slack message formatting
+	lines := strings.Split(slackEscaper.Replace(strings.TrimSuffix(text(s), "\n")), "\n")
+	lines[0] = fmt.Sprintf("Hook `%s` received `%s %s`", s.Hook, s.Method, slackEscaper.Replace(target))
+
+	return strings.Join(lines, "\n")
+}
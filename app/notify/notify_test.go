@@ -0,0 +1,307 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/network"
+)
+
+func testRequest() *models.Request {
+	return &models.Request{
+		Name:        "0abc",
+		Method:      http.MethodPost,
+		Path:        "/foo/events",
+		Query:       "x=1",
+		ContentType: "application/json",
+		BodySize:    2048,
+		RemoteAddr:  "10.0.0.1",
+		Signature:   &models.SignatureCheck{Provider: models.ProviderGitHub, Valid: true},
+		Created:     time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+// run starts the notifier and returns a function waiting for its workers
+func run(n *Notifier) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		n.Run(ctx)
+		close(done)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestNotifyWebhookAndSlack(t *testing.T) {
+	received := make(chan map[string]interface{}, 2)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		payload["_path"] = r.URL.Path
+		received <- payload
+	}))
+	defer ts.Close()
+
+	n := New("http://localhost:8080", SMTP{}, true)
+	stop := run(n)
+	defer stop()
+
+	hook := &models.Hook{Name: "foo", Notifications: &models.Notifications{Webhook: ts.URL + "/webhook", Slack: ts.URL + "/slack"}}
+	n.Notify(hook, testRequest())
+
+	payloads := map[string]map[string]interface{}{}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case p := <-received:
+			payloads[p["_path"].(string)] = p
+		case <-time.After(5 * time.Second):
+			t.Fatal("notification is not received")
+		}
+	}
+
+	webhook := payloads["/webhook"]
+	require.NotNil(t, webhook)
+	assert.Equal(t, "foo", webhook["hook"])
+	assert.Equal(t, "0abc", webhook["request"])
+	assert.Equal(t, "http://localhost:8080/i/foo#0abc", webhook["url"])
+	assert.Equal(t, "POST", webhook["method"])
+	assert.Equal(t, 2048.0, webhook["body_size"])
+	assert.Equal(t, true, webhook["signature_valid"])
+
+	slack := payloads["/slack"]
+	require.NotNil(t, slack)
+	assert.Equal(t, "Hook `foo` received `POST /foo/events?x=1`\n"+
+		"Body: application/json, 2.0 kB\n"+
+		"From: 10.0.0.1\n"+
+		"Signature: valid\n"+
+		"http://localhost:8080/i/foo#0abc", slack["text"])
+
+	// control characters of Slack formatting are escaped
+	req := testRequest()
+	req.Path = "/foo/<!channel>"
+	req.Query = "a=1&b=<2>"
+	assert.Equal(t, "Hook `foo` received `POST /foo/&lt;!channel&gt;?a=1&amp;b=&lt;2&gt;`", strings.SplitN(slackText(n.summary("foo", req)), "\n", 2)[0])
+
+	// hooks without notifications are skipped
+	n.Notify(&models.Hook{Name: "bar"}, testRequest())
+	assert.Len(t, n.queue, 0)
+}
+
+func TestNotifyRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts[r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	n := New("", SMTP{}, true)
+	n.backoff = time.Millisecond
+
+	ctx := context.Background()
+
+	n.deliver(ctx, &job{"webhook", &Summary{}, func(ctx context.Context, s *Summary) error {
+		return n.post(ctx, ts.URL+"/unavailable", s)
+	}})
+
+	n.deliver(ctx, &job{"webhook", &Summary{}, func(ctx context.Context, s *Summary) error {
+		return n.post(ctx, ts.URL+"/missing", s)
+	}})
+
+	assert.Equal(t, map[string]int{"/unavailable": maxAttempts, "/missing": 1}, attempts)
+}
+
+func TestNotifyPrivate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	n := New("", SMTP{}, false)
+
+	err := n.post(context.Background(), ts.URL, &Summary{})
+	assert.ErrorIs(t, err, network.ErrForbiddenAddress)
+
+	var perm permanentError
+	assert.ErrorAs(t, err, &perm) // not retried
+}
+
+func TestNotifyRateLimit(t *testing.T) {
+	n := New("", SMTP{}, true)
+
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+
+	hook := &models.Hook{Name: "foo", Notifications: &models.Notifications{Webhook: "http://localhost", Rate: 2}}
+
+	for i := 0; i < 5; i++ {
+		n.Notify(hook, testRequest())
+	}
+
+	require.Len(t, n.queue, 2)
+
+	// another hook has its own limit
+	n.Notify(&models.Hook{Name: "bar", Notifications: hook.Notifications}, testRequest())
+	require.Len(t, n.queue, 3)
+
+	for i := 0; i < 3; i++ {
+		<-n.queue
+	}
+
+	// the next window reports notifications suppressed in the previous one
+	now = now.Add(time.Minute)
+	n.Notify(hook, testRequest())
+
+	require.Len(t, n.queue, 1)
+	assert.Equal(t, 3, (<-n.queue).summary.Suppressed)
+
+	now = now.Add(time.Minute)
+	n.prune()
+	assert.Len(t, n.windows, 0)
+}
+
+func TestNotifyEmail(t *testing.T) {
+	var (
+		addr string
+		from string
+		to   []string
+		msg  string
+	)
+
+	n := New("http://localhost:8080", SMTP{Host: "smtp.example.com", Username: "user", Password: "secret", From: "Hooks <hooks@example.com>"}, true)
+	n.sendMail = func(ctx context.Context, a string, auth smtp.Auth, f string, t []string, m []byte) error {
+		addr, from, to, msg = a, f, t, string(m)
+		return nil
+	}
+
+	req := testRequest()
+	req.Path = "/foo/\nBcc: spam@example.com"
+
+	require.NoError(t, n.email(context.Background(), []string{"John <john@example.com>", "jane@example.com"}, n.summary("foo", req)))
+
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, "hooks@example.com", from)
+	assert.Equal(t, []string{"john@example.com", "jane@example.com"}, to)
+	assert.Contains(t, msg, "To: \"John\" <john@example.com>, <jane@example.com>\r\n")
+	assert.Contains(t, msg, "Subject: =?utf-8?q?")
+	assert.NotContains(t, strings.SplitN(msg, "\r\n\r\n", 2)[0], "\r\nBcc:")
+	assert.Contains(t, msg, "\r\n\r\nHook foo received POST /foo/")
+	assert.Contains(t, msg, "http://localhost:8080/i/foo#0abc\r\n")
+
+	n = New("", SMTP{}, true)
+	assert.False(t, n.EmailEnabled())
+	assert.ErrorIs(t, n.email(context.Background(), []string{"jane@example.com"}, n.summary("foo", req)), ErrNotConfigured)
+}
+
+// smtpServer serves a single SMTP session passing the received message to the channel
+func smtpServer(t *testing.T, received chan<- string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+
+				received <- string(data)
+
+				_ = tp.PrintfLine("250 OK")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				return
+			default:
+				_ = tp.PrintfLine("502 Unknown command")
+			}
+		}
+	}()
+
+	return l
+}
+
+func TestNotifySend(t *testing.T) {
+	received := make(chan string, 1)
+
+	l := smtpServer(t, received)
+	defer func() { _ = l.Close() }()
+
+	n := New("", SMTP{Host: "localhost", From: "hooks@example.com"}, true)
+
+	err := n.send(context.Background(), l.Addr().String(), nil, "hooks@example.com", []string{"john@example.com"}, []byte("Subject: test\r\n\r\nhello\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Subject: test\n\nhello\n", <-received)
+}
+
+func TestNotifySendCancel(t *testing.T) {
+	// the server accepts connections but never greets
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = l.Close() }()
+
+	n := New("", SMTP{Host: "localhost", From: "hooks@example.com"}, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err = n.send(ctx, l.Addr().String(), nil, "hooks@example.com", []string{"john@example.com"}, []byte("hello"))
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -2,24 +2,42 @@ package app
 
 import (
 	"strings"
+
+	"github.com/dotzero/hooks/app/notify"
 )
 
 // CommonOpts is the options that provided into handlers
 type CommonOpts struct {
-	AppURL     string
-	BoltPath   string
-	BoltTTL    int
-	StaticPath string
-	TmlPath    string
-	TplExt     string
+	AppURL       string
+	StoreType    string
+	BoltPath     string
+	BoltTTL      int
+	MaxTTL       int
+	MaxBodySize  int64
+	MaxRequests  int
+	MaxHookSize  int64
+	StaticPath   string
+	TmlPath      string
+	TplExt       string
+	Version      string
+	AllowPrivate bool
+	SMTP         notify.SMTP
 }
 
 // SetCommon apply the options
 func (c *CommonOpts) SetCommon(commonOpts CommonOpts) {
 	c.AppURL = strings.TrimSuffix(commonOpts.AppURL, "/")
+	c.StoreType = commonOpts.StoreType
 	c.BoltPath = strings.TrimSuffix(commonOpts.BoltPath, "/")
 	c.BoltTTL = commonOpts.BoltTTL
+	c.MaxTTL = commonOpts.MaxTTL
+	c.MaxBodySize = commonOpts.MaxBodySize
+	c.MaxRequests = commonOpts.MaxRequests
+	c.MaxHookSize = commonOpts.MaxHookSize
 	c.StaticPath = strings.TrimSuffix(commonOpts.StaticPath, "/")
 	c.TmlPath = strings.TrimSuffix(commonOpts.TmlPath, "/")
 	c.TplExt = commonOpts.TplExt
+	c.Version = commonOpts.Version
+	c.AllowPrivate = commonOpts.AllowPrivate
+	c.SMTP = commonOpts.SMTP
 }
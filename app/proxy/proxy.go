@@ -0,0 +1,142 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/network"
+)
+
+// ErrInvalidURL is returned when target is not an absolute http(s) url
+var ErrInvalidURL = errors.New("invalid target url")
+
+// via is the pseudonym added to the Via header of outgoing requests to detect loops
+const via = "hooks"
+
+// hopHeaders are not passed to the target, see RFC 7230, section 6.1
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Host",
+	"Content-Length",
+	"Accept-Encoding", // let the transport negotiate and decode compression
+}
+
+// Client re-issues captured requests to target urls
+type Client struct {
+	client      *http.Client
+	maxBodySize int64
+}
+
+// New returns a client with a timeout of the whole exchange,
+// response bodies are stored up to maxBodySize bytes.
+// Targets in the local network are refused unless allowPrivate is set
+func New(timeout time.Duration, maxBodySize int64, allowPrivate bool) *Client {
+	return &Client{
+		client: &http.Client{
+			Transport: network.Transport(timeout, allowPrivate),
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse // record redirects as is
+			},
+		},
+		maxBodySize: maxBodySize,
+	}
+}
+
+// Replay sends the captured hook request to the target url.
+// The request path following the hook name and the query are appended to the target.
+// Failures of the exchange are recorded in the reply, targets in the local network are refused with an error
+func (c *Client) Replay(ctx context.Context, target string, hook string, req *models.Request) (*models.Reply, error) {
+	u, err := targetURL(target, req.Subpath(hook), req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := http.NewRequestWithContext(ctx, req.Method, u, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	out.Header = req.Headers.Clone()
+	if out.Header == nil {
+		out.Header = make(http.Header)
+	}
+
+	RemoveHopHeaders(out.Header)
+	out.Header.Add("Via", "1.1 "+via)
+
+	start := time.Now()
+
+	resp, err := c.client.Do(out)
+	if errors.Is(err, network.ErrForbiddenAddress) {
+		return nil, err
+	}
+
+	if err != nil {
+		return models.NewFailedReply(u, err, time.Since(start)), nil
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	return models.NewReply(u, resp, time.Since(start), c.maxBodySize), nil
+}
+
+// RemoveHopHeaders removes headers which are not passed through the proxy
+func RemoveHopHeaders(h http.Header) {
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+}
+
+// Looped reports whether the request has been already sent by hooks,
+// such requests are not sent again to prevent forwarding loops
+func Looped(h http.Header) bool {
+	for _, value := range h.Values("Via") {
+		for _, hop := range strings.Split(value, ",") {
+			// the hop is "protocol received-by [comment]"
+			if fields := strings.Fields(hop); len(fields) > 1 && fields[1] == via {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// targetURL returns target url with appended subpath and query
+func targetURL(target string, subpath string, query string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidURL, target)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + subpath
+	u.RawPath = ""
+
+	if query != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+
+		u.RawQuery += query
+	}
+
+	u.Fragment = ""
+
+	return u.String(), nil
+}
@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/network"
+)
+
+func TestReplay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/dev/callback/orders", r.URL.Path)
+		assert.Equal(t, "env=dev&foo=bar", r.URL.RawQuery)
+		assert.Equal(t, "baz", r.Header.Get("X-Foo"))
+		assert.Empty(t, r.Header.Get("Connection"))
+		assert.True(t, Looped(r.Header))
+		assert.Equal(t, `{"foo":"bar"}`, string(body))
+
+		w.Header().Set("X-Reply", "ok")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("accepted"))
+	}))
+	defer ts.Close()
+
+	req := &models.Request{
+		Method: http.MethodPost,
+		Path:   "/hook/orders",
+		Query:  "foo=bar",
+		Body:   []byte(`{"foo":"bar"}`),
+		Headers: http.Header{
+			"X-Foo":      {"baz"},
+			"Connection": {"keep-alive"},
+		},
+	}
+
+	c := New(time.Second, 1024, true)
+
+	reply, err := c.Replay(context.Background(), ts.URL+"/dev/callback/?env=dev", "hook", req)
+	assert.NoError(t, err)
+	assert.Equal(t, ts.URL+"/dev/callback/orders?env=dev&foo=bar", reply.URL)
+	assert.Equal(t, http.StatusAccepted, reply.Status)
+	assert.Equal(t, "ok", reply.Headers.Get("X-Reply"))
+	assert.Equal(t, []byte("accepted"), reply.Body)
+	assert.Empty(t, reply.Error)
+}
+
+func TestReplayFailed(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close() // nothing listens anymore
+
+	req := &models.Request{
+		Method: http.MethodGet,
+		Path:   "/hook",
+	}
+
+	c := New(time.Second, 1024, true)
+
+	reply, err := c.Replay(context.Background(), ts.URL, "hook", req)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, reply.Status)
+	assert.NotEmpty(t, reply.Error)
+}
+
+func TestReplayPrivate(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	c := New(time.Second, 1024, false)
+
+	_, err := c.Replay(context.Background(), ts.URL, "hook", &models.Request{Method: http.MethodGet, Path: "/hook"})
+	assert.ErrorIs(t, err, network.ErrForbiddenAddress)
+}
+
+func TestLooped(t *testing.T) {
+	assert.False(t, Looped(http.Header{}))
+	assert.False(t, Looped(http.Header{"Via": {"1.1 proxy (hooks)"}}))
+	assert.True(t, Looped(http.Header{"Via": {"1.1 proxy, HTTP/1.1 hooks"}}))
+	assert.True(t, Looped(http.Header{"Via": {"1.0 proxy", "1.1 hooks"}}))
+}
+
+func TestReplayInvalidURL(t *testing.T) {
+	c := New(time.Second, 1024, true)
+
+	for _, target := range []string{"", "localhost:8080", "ftp://example.com", "http://"} {
+		_, err := c.Replay(context.Background(), target, "hook", &models.Request{})
+		assert.ErrorIs(t, err, ErrInvalidURL, target)
+	}
+}
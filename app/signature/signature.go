@@ -0,0 +1,248 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+const (
+	githubHeader    = "X-Hub-Signature-256"
+	stripeHeader    = "Stripe-Signature"
+	slackHeader     = "X-Slack-Signature"
+	slackTimeHeader = "X-Slack-Request-Timestamp"
+)
+
+// Verifier checks the request signature against the body written to it.
+// The body is hashed as it is written, so it is verified in full even if only a part of it is stored
+type Verifier struct {
+	provider   string
+	mac        hash.Hash // nil when the request could not be verified regardless of the body
+	signatures [][]byte  // candidate signatures of the request
+	timestamp  time.Time // signed timestamp of providers protecting against replays
+	tolerance  time.Duration
+	now        time.Time
+	reason     string
+}
+
+// New returns a verifier of the request with the headers by the hook signature configuration
+func New(cfg *models.Signature, headers http.Header, now time.Time) *Verifier {
+	v := &Verifier{
+		provider:  cfg.Provider,
+		tolerance: cfg.ToleranceDuration(),
+		now:       now,
+	}
+
+	switch cfg.Provider {
+	case models.ProviderGitHub:
+		v.parseGitHub(headers)
+	case models.ProviderStripe:
+		v.parseStripe(headers)
+	case models.ProviderSlack:
+		v.parseSlack(headers)
+	case models.ProviderHMAC:
+		v.parseHMAC(headers, cfg.HeaderName())
+	default:
+		v.reason = fmt.Sprintf("unknown provider %q", cfg.Provider)
+	}
+
+	if v.reason != "" {
+		return v
+	}
+
+	v.mac = hmac.New(sha256.New, []byte(cfg.Secret))
+
+	switch cfg.Provider {
+	case models.ProviderStripe:
+		_, _ = io.WriteString(v.mac, strconv.FormatInt(v.timestamp.Unix(), 10)+".")
+	case models.ProviderSlack:
+		_, _ = io.WriteString(v.mac, "v0:"+strconv.FormatInt(v.timestamp.Unix(), 10)+":")
+	}
+
+	return v
+}
+
+// Write hashes the next part of the request body
+func (v *Verifier) Write(p []byte) (int, error) {
+	if v.mac != nil {
+		_, _ = v.mac.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// Check returns the result of verification of the body written so far
+func (v *Verifier) Check() *models.SignatureCheck {
+	check := &models.SignatureCheck{
+		Provider: v.provider,
+		Reason:   v.reason,
+	}
+
+	if v.mac == nil {
+		return check
+	}
+
+	sum := v.mac.Sum(nil)
+
+	matched := false
+	for _, sig := range v.signatures {
+		if hmac.Equal(sum, sig) {
+			matched = true
+			break
+		}
+	}
+
+	switch {
+	case !matched:
+		check.Reason = "signature does not match the body, check the secret and that the body is not modified"
+	case !v.timestamp.IsZero() && absDuration(v.now.Sub(v.timestamp)) > v.tolerance:
+		check.Reason = fmt.Sprintf("timestamp %s is outside the tolerance of %s", v.timestamp.UTC().Format(time.RFC3339), v.tolerance)
+	default:
+		check.Valid = true
+	}
+
+	return check
+}
+
+// parseGitHub parses "sha256=<hex>" signature
+func (v *Verifier) parseGitHub(headers http.Header) {
+	value := headers.Get(githubHeader)
+	if value == "" {
+		v.reason = missingHeader(githubHeader)
+		return
+	}
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(value, "sha256="))
+	if err != nil || !strings.HasPrefix(value, "sha256=") {
+		v.reason = malformedHeader(githubHeader)
+		return
+	}
+
+	v.signatures = [][]byte{sig}
+}
+
+// parseStripe parses "t=<unix>,v1=<hex>,v1=<hex>" signature, schemes other than v1 are ignored
+func (v *Verifier) parseStripe(headers http.Header) {
+	value := headers.Get(stripeHeader)
+	if value == "" {
+		v.reason = missingHeader(stripeHeader)
+		return
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		switch parts[0] {
+		case "t":
+			ts, err := parseTimestamp(parts[1])
+			if err != nil {
+				v.reason = malformedHeader(stripeHeader)
+				return
+			}
+
+			v.timestamp = ts
+		case "v1":
+			if sig, err := hex.DecodeString(parts[1]); err == nil {
+				v.signatures = append(v.signatures, sig)
+			}
+		}
+	}
+
+	if v.timestamp.IsZero() {
+		v.reason = fmt.Sprintf("%s header has no timestamp", stripeHeader)
+		return
+	}
+
+	if len(v.signatures) == 0 {
+		v.reason = fmt.Sprintf("%s header has no v1 signatures", stripeHeader)
+	}
+}
+
+// parseSlack parses "v0=<hex>" signature and the request timestamp
+func (v *Verifier) parseSlack(headers http.Header) {
+	value := headers.Get(slackHeader)
+	if value == "" {
+		v.reason = missingHeader(slackHeader)
+		return
+	}
+
+	tsValue := headers.Get(slackTimeHeader)
+	if tsValue == "" {
+		v.reason = missingHeader(slackTimeHeader)
+		return
+	}
+
+	ts, err := parseTimestamp(tsValue)
+	if err != nil {
+		v.reason = malformedHeader(slackTimeHeader)
+		return
+	}
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(value, "v0="))
+	if err != nil || !strings.HasPrefix(value, "v0=") {
+		v.reason = malformedHeader(slackHeader)
+		return
+	}
+
+	v.timestamp = ts
+	v.signatures = [][]byte{sig}
+}
+
+// parseHMAC parses hex or base64 signature, optionally prefixed by "sha256="
+func (v *Verifier) parseHMAC(headers http.Header, header string) {
+	value := strings.TrimPrefix(strings.TrimSpace(headers.Get(header)), "sha256=")
+	if value == "" {
+		v.reason = missingHeader(header)
+		return
+	}
+
+	if sig, err := hex.DecodeString(value); err == nil {
+		v.signatures = append(v.signatures, sig)
+	}
+
+	if sig, err := base64.StdEncoding.DecodeString(value); err == nil {
+		v.signatures = append(v.signatures, sig)
+	}
+
+	if len(v.signatures) == 0 {
+		v.reason = malformedHeader(header)
+	}
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	return time.Unix(sec, 0), nil
+}
+
+func missingHeader(name string) string {
+	return fmt.Sprintf("%s header is missing", name)
+}
+
+func malformedHeader(name string) string {
+	return fmt.Sprintf("%s header is malformed", name)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
@@ -0,0 +1,164 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+const (
+	testSecret = "secret"
+	testBody   = `{"event":"ping"}`
+)
+
+func TestVerifyGitHub(t *testing.T) {
+	cfg := &models.Signature{Provider: models.ProviderGitHub, Secret: testSecret}
+	now := time.Now()
+
+	tbl := []struct {
+		header string
+		valid  bool
+		reason string
+	}{
+		{"sha256=" + sign(testBody), true, ""},
+		{"sha256=" + sign("other"), false, "does not match"},
+		{"sha1=" + sign(testBody), false, "malformed"},
+		{"", false, "missing"},
+	}
+
+	for _, tt := range tbl {
+		headers := http.Header{}
+		if tt.header != "" {
+			headers.Set("X-Hub-Signature-256", tt.header)
+		}
+
+		check := verify(cfg, headers, testBody, now)
+
+		assert.Equal(t, models.ProviderGitHub, check.Provider)
+		assert.Equal(t, tt.valid, check.Valid, tt.header)
+		assert.Contains(t, check.Reason, tt.reason, tt.header)
+	}
+}
+
+func TestVerifyStripe(t *testing.T) {
+	cfg := &models.Signature{Provider: models.ProviderStripe, Secret: testSecret}
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	old := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+
+	tbl := []struct {
+		header string
+		valid  bool
+		reason string
+	}{
+		{"t=" + ts + ",v1=" + sign(ts+"."+testBody), true, ""},
+		{"t=" + ts + ",v1=" + sign("wrong") + ",v1=" + sign(ts+"."+testBody) + ",v0=abc", true, ""}, // rolled secret
+		{"t=" + ts + ",v1=" + sign(testBody), false, "does not match"},
+		{"t=" + old + ",v1=" + sign(old+"."+testBody), false, "outside the tolerance"},
+		{"v1=" + sign(testBody), false, "no timestamp"},
+		{"t=" + ts, false, "no v1 signatures"},
+		{"t=now,v1=" + sign(testBody), false, "malformed"},
+		{"", false, "missing"},
+	}
+
+	for _, tt := range tbl {
+		headers := http.Header{}
+		if tt.header != "" {
+			headers.Set("Stripe-Signature", tt.header)
+		}
+
+		check := verify(cfg, headers, testBody, now)
+
+		assert.Equal(t, tt.valid, check.Valid, tt.header)
+		assert.Contains(t, check.Reason, tt.reason, tt.header)
+	}
+
+	cfg.Tolerance = 7200
+	headers := http.Header{"Stripe-Signature": {"t=" + old + ",v1=" + sign(old+"."+testBody)}}
+	assert.True(t, verify(cfg, headers, testBody, now).Valid)
+}
+
+func TestVerifySlack(t *testing.T) {
+	cfg := &models.Signature{Provider: models.ProviderSlack, Secret: testSecret}
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	headers := http.Header{
+		"X-Slack-Signature":         {"v0=" + sign("v0:"+ts+":"+testBody)},
+		"X-Slack-Request-Timestamp": {ts},
+	}
+
+	check := verify(cfg, headers, testBody, now)
+	assert.True(t, check.Valid)
+	assert.Empty(t, check.Reason)
+
+	check = verify(cfg, headers, testBody, now.Add(10*time.Minute))
+	assert.False(t, check.Valid)
+	assert.Contains(t, check.Reason, "outside the tolerance")
+
+	headers.Del("X-Slack-Request-Timestamp")
+
+	check = verify(cfg, headers, testBody, now)
+	assert.False(t, check.Valid)
+	assert.Equal(t, "X-Slack-Request-Timestamp header is missing", check.Reason)
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	cfg := &models.Signature{Provider: models.ProviderHMAC, Secret: testSecret, Header: "x-shopify-hmac-sha256"}
+	now := time.Now()
+
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	_, _ = mac.Write([]byte(testBody))
+	sum := mac.Sum(nil)
+
+	for _, value := range []string{hex.EncodeToString(sum), "sha256=" + hex.EncodeToString(sum), base64.StdEncoding.EncodeToString(sum)} {
+		headers := http.Header{"X-Shopify-Hmac-Sha256": {value}}
+		assert.True(t, verify(cfg, headers, testBody, now).Valid, value)
+	}
+
+	check := verify(cfg, http.Header{"X-Signature": {hex.EncodeToString(sum)}}, testBody, now)
+	assert.False(t, check.Valid)
+	assert.Equal(t, "X-Shopify-Hmac-Sha256 header is missing", check.Reason)
+
+	cfg.Header = ""
+	assert.True(t, verify(cfg, http.Header{"X-Signature": {hex.EncodeToString(sum)}}, testBody, now).Valid)
+}
+
+func TestVerifierWrite(t *testing.T) {
+	cfg := &models.Signature{Provider: models.ProviderGitHub, Secret: testSecret}
+	body := strings.Repeat("a", 1<<20)
+	headers := http.Header{"X-Hub-Signature-256": {"sha256=" + sign(body)}}
+
+	v := New(cfg, headers, time.Now())
+
+	// the body is written in parts as it is read from the request
+	for i := 0; i < len(body); i += 4096 {
+		_, _ = v.Write([]byte(body[i : i+4096]))
+	}
+
+	assert.True(t, v.Check().Valid)
+}
+
+func verify(cfg *models.Signature, headers http.Header, body string, now time.Time) *models.SignatureCheck {
+	v := New(cfg, headers, now)
+	_, _ = v.Write([]byte(body))
+
+	return v.Check()
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	_, _ = mac.Write([]byte(payload))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,350 @@
+package snippet
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/proxy"
+)
+
+// Snippet is a ready-to-run code reproducing the captured request
+type Snippet struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Code  string `json:"code"`
+}
+
+// generator renders the snippet of the request to the url
+type generator struct {
+	name   string
+	title  string
+	render func(u string, req *models.Request, headers []header) string
+}
+
+// header is a single header value sent by the snippet
+type header struct {
+	name  string
+	value string
+}
+
+var generators = []generator{
+	{"curl", "cURL", curl},
+	{"httpie", "HTTPie", httpie},
+	{"go", "Go", golang},
+	{"python", "Python", python},
+	{"fetch", "JavaScript", fetch},
+}
+
+// All returns snippets of all languages sending the request to the hook at baseURL
+func All(baseURL string, req *models.Request) []*Snippet {
+	snippets := make([]*Snippet, 0, len(generators))
+
+	for _, g := range generators {
+		snippets = append(snippets, g.snippet(baseURL, req))
+	}
+
+	return snippets
+}
+
+// Get returns the snippet of the language by its name
+func Get(name string, baseURL string, req *models.Request) (*Snippet, bool) {
+	for _, g := range generators {
+		if g.name == name {
+			return g.snippet(baseURL, req), true
+		}
+	}
+
+	return nil, false
+}
+
+// Names returns names of the supported languages
+func Names() []string {
+	names := make([]string, 0, len(generators))
+	for _, g := range generators {
+		names = append(names, g.name)
+	}
+
+	return names
+}
+
+// Languages returns snippets of the supported languages without code
+func Languages() []*Snippet {
+	snippets := make([]*Snippet, 0, len(generators))
+	for _, g := range generators {
+		snippets = append(snippets, &Snippet{Name: g.name, Title: g.title})
+	}
+
+	return snippets
+}
+
+func (g generator) snippet(baseURL string, req *models.Request) *Snippet {
+	u := baseURL + req.Path
+	if req.Query != "" {
+		u += "?" + req.Query
+	}
+
+	code := g.render(u, req, headers(req))
+	if req.BodyTruncated {
+		code = fmt.Sprintf("%s only %d of %d bytes of the body are stored\n%s", comment(g.name), len(req.Body), req.BodySize, code)
+	}
+
+	return &Snippet{Name: g.name, Title: g.title, Code: code}
+}
+
+// headers returns the request headers sorted by names without the ones set by clients
+func headers(req *models.Request) []header {
+	h := req.Headers.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+
+	proxy.RemoveHopHeaders(h)
+
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var result []header
+
+	for _, name := range names {
+		for _, value := range h[name] {
+			result = append(result, header{name, value})
+		}
+	}
+
+	return result
+}
+
+func curl(u string, req *models.Request, headers []header) string {
+	var b strings.Builder
+
+	if len(req.Body) > 0 && req.IsBinary() {
+		fmt.Fprintf(&b, "echo %s | base64 -d | ", base64.StdEncoding.EncodeToString(req.Body))
+	}
+
+	b.WriteString("curl")
+
+	switch {
+	case req.Method == http.MethodHead:
+		b.WriteString(" --head")
+	case req.Method != http.MethodGet || len(req.Body) > 0:
+		b.WriteString(" -X " + req.Method)
+	}
+
+	b.WriteString(" " + shellQuote(u))
+
+	for _, h := range headers {
+		b.WriteString(" \\\n  -H " + shellQuote(h.name+": "+h.value))
+	}
+
+	switch {
+	case len(req.Body) == 0:
+	case req.IsBinary():
+		b.WriteString(" \\\n  --data-binary @-")
+	default:
+		b.WriteString(" \\\n  --data-binary " + shellQuote(string(req.Body)))
+	}
+
+	return b.String()
+}
+
+func httpie(u string, req *models.Request, headers []header) string {
+	var b strings.Builder
+
+	switch {
+	case len(req.Body) == 0:
+	case req.IsBinary():
+		fmt.Fprintf(&b, "echo %s | base64 -d | ", base64.StdEncoding.EncodeToString(req.Body))
+	default:
+		fmt.Fprintf(&b, "printf %%s %s | ", shellQuote(string(req.Body)))
+	}
+
+	b.WriteString("http " + req.Method + " " + shellQuote(u))
+
+	for _, h := range headers {
+		b.WriteString(" \\\n  " + shellQuote(h.name+":"+h.value))
+	}
+
+	return b.String()
+}
+
+func golang(u string, req *models.Request, headers []header) string {
+	var b strings.Builder
+
+	imports := []string{"fmt", "io", "net/http"}
+	body := "nil"
+
+	if len(req.Body) > 0 {
+		imports = append(imports, "strings")
+		body = "body"
+	}
+
+	b.WriteString("package main\n\nimport (\n")
+
+	for _, pkg := range imports {
+		fmt.Fprintf(&b, "\t%s\n", strconv.Quote(pkg))
+	}
+
+	b.WriteString(")\n\nfunc main() {\n")
+
+	if len(req.Body) > 0 {
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n\n", strconv.Quote(string(req.Body)))
+	}
+
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(req.Method), strconv.Quote(u), body)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n\n")
+
+	for _, h := range headers {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", strconv.Quote(h.name), strconv.Quote(h.value))
+	}
+
+	if len(headers) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tout, _ := io.ReadAll(resp.Body)\n")
+	b.WriteString("\tfmt.Println(resp.Status, string(out))\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func python(u string, req *models.Request, headers []header) string {
+	var b strings.Builder
+
+	b.WriteString("import requests\n\n")
+	b.WriteString("response = requests.request(\n")
+	fmt.Fprintf(&b, "    %s,\n    %s,\n", jsString(req.Method), jsString(u))
+
+	if len(headers) > 0 {
+		b.WriteString("    headers={\n")
+
+		// requests takes a single value per header, multiple values are joined
+		for _, h := range joinHeaders(headers) {
+			fmt.Fprintf(&b, "        %s: %s,\n", jsString(h.name), jsString(h.value))
+		}
+
+		b.WriteString("    },\n")
+	}
+
+	switch {
+	case len(req.Body) == 0:
+	case req.IsBinary():
+		fmt.Fprintf(&b, "    data=%s,\n", pythonBytes(req.Body))
+	default:
+		fmt.Fprintf(&b, "    data=%s.encode(),\n", jsString(string(req.Body)))
+	}
+
+	b.WriteString(")\n")
+	b.WriteString("print(response.status_code, response.text)\n")
+
+	return b.String()
+}
+
+func fetch(u string, req *models.Request, headers []header) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", jsString(u))
+	fmt.Fprintf(&b, "  method: %s,\n", jsString(req.Method))
+
+	if len(headers) > 0 {
+		b.WriteString("  headers: {\n")
+
+		for _, h := range joinHeaders(headers) {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsString(h.name), jsString(h.value))
+		}
+
+		b.WriteString("  },\n")
+	}
+
+	switch {
+	case len(req.Body) == 0:
+	case req.IsBinary():
+		fmt.Fprintf(&b, "  body: Uint8Array.from(atob(%s), (c) => c.charCodeAt(0)),\n",
+			jsString(base64.StdEncoding.EncodeToString(req.Body)))
+	default:
+		fmt.Fprintf(&b, "  body: %s,\n", jsString(string(req.Body)))
+	}
+
+	b.WriteString("});\n")
+	b.WriteString("console.log(response.status, await response.text());\n")
+
+	return b.String()
+}
+
+// joinHeaders joins multiple values of the same header by comma
+func joinHeaders(headers []header) []header {
+	var result []header
+
+	for _, h := range headers {
+		if n := len(result); n > 0 && result[n-1].name == h.name {
+			result[n-1].value += ", " + h.value
+			continue
+		}
+
+		result = append(result, h)
+	}
+
+	return result
+}
+
+// shellQuote quotes the value for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// jsString returns double-quoted string literal valid in JavaScript and Python
+func jsString(s string) string {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// pythonBytes returns bytes literal of Python
+func pythonBytes(data []byte) string {
+	var b strings.Builder
+
+	b.WriteString(`b"`)
+
+	for _, c := range data {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+
+	b.WriteString(`"`)
+
+	return b.String()
+}
+
+// comment returns the line comment prefix of the language
+func comment(name string) string {
+	if name == "go" || name == "fetch" {
+		return "//"
+	}
+
+	return "#"
+}
@@ -0,0 +1,139 @@
+package snippet
+
+import (
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+func testRequest() *models.Request {
+	return &models.Request{
+		Method: http.MethodPost,
+		Path:   "/foo/events",
+		Query:  "x=1",
+		Body:   []byte(`{"msg":"it's \"ok\""}`),
+		Headers: http.Header{
+			"Content-Type":   {"application/json"},
+			"Content-Length": {"20"},
+			"X-Tag":          {"a", "b"},
+		},
+	}
+}
+
+func TestCurl(t *testing.T) {
+	s, ok := Get("curl", "http://localhost:8080", testRequest())
+	require.True(t, ok)
+
+	assert.Equal(t, `curl -X POST 'http://localhost:8080/foo/events?x=1' \
+  -H 'Content-Type: application/json' \
+  -H 'X-Tag: a' \
+  -H 'X-Tag: b' \
+  --data-binary '{"msg":"it'\''s \"ok\""}'`, s.Code)
+
+	s, _ = Get("curl", "http://localhost:8080", &models.Request{Method: http.MethodGet, Path: "/foo"})
+	assert.Equal(t, `curl 'http://localhost:8080/foo'`, s.Code)
+
+	s, _ = Get("curl", "", &models.Request{Method: http.MethodPut, Path: "/foo", Body: []byte{0x00, 0xff}})
+	assert.Equal(t, "echo AP8= | base64 -d | curl -X PUT '/foo' \\\n  --data-binary @-", s.Code)
+}
+
+func TestHTTPie(t *testing.T) {
+	s, ok := Get("httpie", "http://localhost:8080", testRequest())
+	require.True(t, ok)
+
+	assert.Equal(t, `printf %s '{"msg":"it'\''s \"ok\""}' | http POST 'http://localhost:8080/foo/events?x=1' \
+  'Content-Type:application/json' \
+  'X-Tag:a' \
+  'X-Tag:b'`, s.Code)
+}
+
+func TestGo(t *testing.T) {
+	for _, req := range []*models.Request{testRequest(), {Method: http.MethodGet, Path: "/foo"}, {Method: http.MethodPut, Body: []byte{0x00, 0xff}}} {
+		s, ok := Get("go", "http://localhost:8080", req)
+		require.True(t, ok)
+
+		_, err := parser.ParseFile(token.NewFileSet(), "main.go", s.Code, 0)
+		assert.NoError(t, err, s.Code)
+	}
+
+	s, _ := Get("go", "http://localhost:8080", testRequest())
+	assert.Contains(t, s.Code, "body := strings.NewReader(\"{\\\"msg\\\":\\\"it's \\\\\\\"ok\\\\\\\"\\\"}\")")
+	assert.Contains(t, s.Code, `req.Header.Add("X-Tag", "b")`)
+	assert.NotContains(t, s.Code, "Content-Length")
+}
+
+func TestPython(t *testing.T) {
+	s, ok := Get("python", "http://localhost:8080", testRequest())
+	require.True(t, ok)
+
+	assert.Equal(t, `import requests
+
+response = requests.request(
+    "POST",
+    "http://localhost:8080/foo/events?x=1",
+    headers={
+        "Content-Type": "application/json",
+        "X-Tag": "a, b",
+    },
+    data="{\"msg\":\"it's \\\"ok\\\"\"}".encode(),
+)
+print(response.status_code, response.text)
+`, s.Code)
+
+	s, _ = Get("python", "", &models.Request{Method: http.MethodPut, Body: []byte{0x00, '"', 'a', 0xff}})
+	assert.Contains(t, s.Code, `data=b"\x00\"a\xff",`)
+}
+
+func TestFetch(t *testing.T) {
+	s, ok := Get("fetch", "http://localhost:8080", testRequest())
+	require.True(t, ok)
+
+	assert.Equal(t, `const response = await fetch("http://localhost:8080/foo/events?x=1", {
+  method: "POST",
+  headers: {
+    "Content-Type": "application/json",
+    "X-Tag": "a, b",
+  },
+  body: "{\"msg\":\"it's \\\"ok\\\"\"}",
+});
+console.log(response.status, await response.text());
+`, s.Code)
+}
+
+func TestAll(t *testing.T) {
+	req := testRequest()
+	req.BodyTruncated = true
+	req.BodySize = 100
+
+	snippets := All("http://localhost:8080", req)
+	require.Len(t, snippets, len(Names()))
+
+	for i, s := range snippets {
+		assert.Equal(t, Names()[i], s.Name)
+		assert.Equal(t, Languages()[i].Title, s.Title)
+		assert.Contains(t, s.Code, "of 100 bytes of the body are stored")
+	}
+
+	_, ok := Get("ruby", "", req)
+	assert.False(t, ok)
+}
+
+func TestShellQuote(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+
+	for _, s := range []string{"", "plain", "it's", `"$HOME" $(id) \n`, "multi\nline '' `x`"} {
+		out, err := exec.Command(sh, "-c", "printf %s "+shellQuote(s)).Output()
+		require.NoError(t, err)
+		assert.Equal(t, s, string(out))
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"sort"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
@@ -16,7 +15,8 @@ import (
 
 // BoltDB is a wrapper over Bolt DB
 type BoltDB struct {
-	db *bolt.DB
+	db   *bolt.DB
+	opts Options
 }
 
 var (
@@ -24,21 +24,38 @@ var (
 	BucketHooks = []byte("hooks")
 	// BucketReqs name of the requests bucket
 	BucketReqs = []byte("requests")
-	// BucketTTL name of the ttl bucket
+	// BucketTTL name of the ttl bucket, keyed by creation time of hooks without own expiry
 	BucketTTL = []byte("ttl")
+	// BucketExpires name of the expiry bucket, keyed by expiry time of hooks
+	BucketExpires = []byte("expires")
 	// BucketCounters name of the counters bucket
 	BucketCounters = []byte("counters")
+	// BucketIndex name of the requests index bucket, keyed by creation time of requests with their sizes
+	BucketIndex = []byte("index")
+	// BucketSizes name of the bucket of total sizes of requests of hooks, keyed by hook names
+	BucketSizes = []byte("sizes")
+	// BucketPublic name of the public hooks index bucket, keyed by creation time of hooks
+	BucketPublic = []byte("public")
+	// BucketOwners name of the owned hooks index bucket, nested by owners and keyed by creation time of hooks
+	BucketOwners = []byte("owners")
+	// BucketUsers name of the users bucket
+	BucketUsers = []byte("users")
+	// BucketSessions name of the sessions bucket
+	BucketSessions = []byte("sessions")
 )
 
-// New returns a wrapper over Bolt DB
-func New(path string) (*BoltDB, error) {
+// NewBoltDB returns a wrapper over Bolt DB
+func NewBoltDB(path string, options ...Option) (*BoltDB, error) {
 	db, err := bolt.Open(path, 0o600, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// ensure buckets exists
-	buckets := [][]byte{BucketHooks, BucketReqs, BucketTTL, BucketCounters}
+	buckets := [][]byte{
+		BucketHooks, BucketReqs, BucketTTL, BucketExpires, BucketCounters, BucketIndex,
+		BucketSizes, BucketOwners, BucketUsers, BucketSessions,
+	}
 
 	err = db.Update(func(tx *bolt.Tx) error {
 		for _, name := range buckets {
@@ -52,9 +69,60 @@ func New(path string) (*BoltDB, error) {
 		return nil, err
 	}
 
-	return &BoltDB{
-		db: db,
-	}, nil
+	b := &BoltDB{
+		db:   db,
+		opts: newOptions(options),
+	}
+
+	if err := b.migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate: %w", err)
+	}
+
+	return b, nil
+}
+
+// migrate indexes hooks and requests stored before the indexes existed
+func (b *BoltDB) migrate() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(BucketPublic) == nil {
+			if err := b.indexPublic(tx); err != nil {
+				return err
+			}
+		}
+
+		bReqs := tx.Bucket(BucketReqs)
+
+		return bReqs.ForEach(func(k, v []byte) error {
+			if v != nil {
+				return nil // not a bucket
+			}
+
+			_, err := b.indexBucket(tx, string(k), bReqs.Bucket(k))
+
+			return err
+		})
+	})
+}
+
+// indexPublic creates the public hooks index from all stored hooks
+func (b *BoltDB) indexPublic(tx *bolt.Tx) error {
+	bPublic, err := tx.CreateBucket(BucketPublic)
+	if err != nil {
+		return fmt.Errorf("failed to create `%s` bucket: %w", BucketPublic, err)
+	}
+
+	return tx.Bucket(BucketHooks).ForEach(func(k, v []byte) error {
+		var hook models.Hook
+		if err := json.Unmarshal(v, &hook); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		if hook.Private {
+			return nil
+		}
+
+		return bPublic.Put(indexKey(hook.Created, hook.Name), k)
+	})
 }
 
 // Close releases all database resources
@@ -62,12 +130,15 @@ func (b *BoltDB) Close() error {
 	return b.db.Close()
 }
 
-// Hook returns hook model by name
+// Hook returns hook model by name or nil if hook is not exists
 func (b *BoltDB) Hook(name string) (*models.Hook, error) {
 	var hook *models.Hook
 
 	err := b.db.View(func(tx *bolt.Tx) error {
 		bHooks := tx.Bucket(BucketHooks)
+		if bHooks.Get([]byte(name)) == nil {
+			return nil
+		}
 
 		return b.load(bHooks, name, &hook)
 	})
@@ -75,17 +146,20 @@ func (b *BoltDB) Hook(name string) (*models.Hook, error) {
 	return hook, err
 }
 
-// PutHook save hook model into storage
+// PutHook save a new hook model into storage, ErrHookExists is returned if the name is taken.
+// The name is checked and taken in the same writable transaction
 func (b *BoltDB) PutHook(hook *models.Hook) error {
 	return b.db.Update(func(tx *bolt.Tx) error {
 		bHooks := tx.Bucket(BucketHooks)
+		if bHooks.Get([]byte(hook.Name)) != nil {
+			return ErrHookExists
+		}
+
 		if err := b.save(bHooks, hook.Name, hook); err != nil {
 			return err
 		}
 
-		bTTL := tx.Bucket(BucketTTL)
-		key := []byte(hook.Created.Format(time.RFC3339Nano))
-		if err := bTTL.Put(key, []byte(hook.Name)); err != nil {
+		if err := b.indexHook(tx, hook); err != nil {
 			return err
 		}
 
@@ -96,128 +170,609 @@ func (b *BoltDB) PutHook(hook *models.Hook) error {
 	})
 }
 
-// RecentHooks returns recent public hooks
-func (b *BoltDB) RecentHooks(max int) ([]*models.Hook, error) {
-	hooks := make([]*models.Hook, 0, max)
+// UpdateHook save changes of existing hook model into storage
+func (b *BoltDB) UpdateHook(hook *models.Hook) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bHooks := tx.Bucket(BucketHooks)
 
-	err := b.db.View(func(tx *bolt.Tx) error {
+		if bHooks.Get([]byte(hook.Name)) == nil {
+			return fmt.Errorf("no value for %s", hook.Name)
+		}
+
+		var prev *models.Hook
+		if err := b.load(bHooks, hook.Name, &prev); err != nil {
+			return err
+		}
+
+		// keep indexes in sync in case the visibility, the owner or the expiry has been changed
+		if err := b.unindexHook(tx, prev); err != nil {
+			return err
+		}
+
+		if err := b.indexHook(tx, hook); err != nil {
+			return err
+		}
+
+		return b.save(bHooks, hook.Name, hook)
+	})
+}
+
+// RenameHook renames hook keeping its requests, ErrHookExists is returned if the new name is taken
+func (b *BoltDB) RenameHook(name string, newName string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
 		bHooks := tx.Bucket(BucketHooks)
+		if bHooks.Get([]byte(name)) == nil {
+			return fmt.Errorf("no value for %s", name)
+		}
 
-		return bHooks.ForEach(func(k, v []byte) error {
-			var hook models.Hook
+		if bHooks.Get([]byte(newName)) != nil {
+			return ErrHookExists
+		}
 
-			err := json.Unmarshal(v, &hook)
-			if err != nil {
-				return fmt.Errorf("failed to unmarshal: %w", err)
-			}
+		var hook *models.Hook
+		if err := b.load(bHooks, name, &hook); err != nil {
+			return err
+		}
+
+		if err := b.unindexHook(tx, hook); err != nil {
+			return err
+		}
+
+		if err := bHooks.Delete([]byte(name)); err != nil {
+			return err
+		}
+
+		hook.Name = newName
+
+		if err := b.save(bHooks, newName, hook); err != nil {
+			return err
+		}
+
+		if err := b.indexHook(tx, hook); err != nil {
+			return err
+		}
+
+		size, err := moveRequests(tx, name, newName)
+		if err != nil {
+			return err
+		}
+
+		bSizes := tx.Bucket(BucketSizes)
+		if err := bSizes.Delete([]byte(name)); err != nil {
+			return err
+		}
+
+		if err := bSizes.Put([]byte(newName), i64tob(size)); err != nil {
+			return err
+		}
 
-			if !hook.Private {
-				hooks = append(hooks, &hook)
+		bCounters := tx.Bucket(BucketCounters)
+		if count := bCounters.Get([]byte(name)); count != nil {
+			if err := bCounters.Put([]byte(newName), append([]byte(nil), count...)); err != nil {
+				return err
 			}
+		}
+
+		return bCounters.Delete([]byte(name))
+	})
+}
 
+// DeleteHook deletes hook with its requests
+func (b *BoltDB) DeleteHook(name string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(BucketHooks).Get([]byte(name)) == nil {
 			return nil
-		})
+		}
+
+		return b.deleteHooks(tx, [][]byte{[]byte(name)})
+	})
+}
+
+// UserHooks returns hooks owned by the user walking the owner index newest first
+func (b *BoltDB) UserHooks(user string) ([]*models.Hook, error) {
+	hooks := make([]*models.Hook, 0)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bOwner := tx.Bucket(BucketOwners).Bucket([]byte(user))
+		if bOwner == nil {
+			return nil
+		}
+
+		bHooks := tx.Bucket(BucketHooks)
+		c := bOwner.Cursor()
+
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			if bHooks.Get(v) == nil {
+				continue // stale index entry
+			}
+
+			var hook *models.Hook
+			if err := b.load(bHooks, string(v), &hook); err != nil {
+				return err
+			}
+
+			hooks = append(hooks, hook)
+		}
+
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Slice(hooks, func(i, j int) bool {
-		return hooks[i].Created.After(hooks[j].Created)
-	})
+	return hooks, nil
+}
+
+// RecentHooks returns recent public hooks walking the public index newest first
+func (b *BoltDB) RecentHooks(max int) ([]*models.Hook, error) {
+	hooks := make([]*models.Hook, 0, max)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bHooks := tx.Bucket(BucketHooks)
+		c := tx.Bucket(BucketPublic).Cursor()
+
+		for k, v := c.Last(); k != nil && len(hooks) < max; k, v = c.Prev() {
+			data := bHooks.Get(v)
+			if data == nil {
+				continue // stale index entry
+			}
+
+			var hook models.Hook
+			if err := json.Unmarshal(data, &hook); err != nil {
+				return fmt.Errorf("failed to unmarshal: %w", err)
+			}
 
-	if len(hooks) > max {
-		return hooks[0:max], nil
+			hooks = append(hooks, &hook)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return hooks, nil
 }
 
-// SweepHooks performs a batch delete of all bucket items using the keys picked up from expired func
+// SweepHooks performs a batch delete of all bucket items using the keys picked up from expired func.
+// Hooks without own expiry are expired after maxAge since creation
 func (b *BoltDB) SweepHooks(maxAge time.Duration) (err error) {
 	keys, err := b.Expired(BucketTTL, maxAge)
-	if err != nil || len(keys) == 0 {
+	if err != nil {
+		return
+	}
+
+	expired, err := b.Expired(BucketExpires, 0)
+	if err != nil {
+		return
+	}
+
+	keys = append(keys, expired...)
+	if len(keys) == 0 {
 		return
 	}
 
 	return b.db.Update(func(tx *bolt.Tx) error {
-		bHooks := tx.Bucket(BucketHooks)
-		bReqs := tx.Bucket(BucketReqs)
-		bCounters := tx.Bucket(BucketCounters)
+		return b.deleteHooks(tx, keys)
+	})
+}
+
+// deleteHooks deletes hooks by names with their requests and index entries
+func (b *BoltDB) deleteHooks(tx *bolt.Tx, keys [][]byte) error {
+	bHooks := tx.Bucket(BucketHooks)
+	bReqs := tx.Bucket(BucketReqs)
+	bIndex := tx.Bucket(BucketIndex)
+	bCounters := tx.Bucket(BucketCounters)
+	bSizes := tx.Bucket(BucketSizes)
 
-		for _, key := range keys {
-			if err = bHooks.Delete(key); err != nil {
+	deleted := 0
+
+	for _, key := range keys {
+		if bHooks.Get(key) != nil {
+			var hook *models.Hook
+			if err := b.load(bHooks, string(key), &hook); err != nil {
 				return err
 			}
 
-			if err = bReqs.DeleteBucket(key); err != nil {
+			if err := b.unindexHook(tx, hook); err != nil {
+				return err
+			}
+
+			deleted++ // keys of hooks deleted meanwhile are not counted twice
+		}
+
+		if err := bHooks.Delete(key); err != nil {
+			return err
+		}
+
+		for _, bkt := range []*bolt.Bucket{bReqs, bIndex} {
+			if err := bkt.DeleteBucket(key); err != nil {
 				if !errors.Is(err, bolt.ErrBucketNotFound) {
 					return err
 				}
 			}
+		}
+
+		if err := bCounters.Delete(key); err != nil {
+			return err
+		}
 
-			if err = bCounters.Delete(key); err != nil {
+		if err := bSizes.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	count := btoi(bCounters.Get(BucketHooks)) - deleted
+
+	return bCounters.Put(BucketHooks, itob(count))
+}
+
+// indexHook adds hook entries into the expiry, public and owner indexes
+func (b *BoltDB) indexHook(tx *bolt.Tx, hook *models.Hook) error {
+	if bkt, key := ttlKey(hook); bkt != nil {
+		if err := tx.Bucket(bkt).Put(key, []byte(hook.Name)); err != nil {
+			return err
+		}
+	}
+
+	if !hook.Private {
+		if err := tx.Bucket(BucketPublic).Put(indexKey(hook.Created, hook.Name), []byte(hook.Name)); err != nil {
+			return err
+		}
+	}
+
+	if hook.Owner != "" {
+		bOwner, err := tx.Bucket(BucketOwners).CreateBucketIfNotExists([]byte(hook.Owner))
+		if err != nil {
+			return err
+		}
+
+		if err := bOwner.Put(indexKey(hook.Created, hook.Name), []byte(hook.Name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unindexHook deletes hook entries from the expiry, public and owner indexes
+func (b *BoltDB) unindexHook(tx *bolt.Tx, hook *models.Hook) error {
+	if bkt, key := ttlKey(hook); bkt != nil {
+		bTTL := tx.Bucket(bkt)
+
+		// hooks created at the same time share the key
+		if bytes.Equal(bTTL.Get(key), []byte(hook.Name)) {
+			if err := bTTL.Delete(key); err != nil {
 				return err
 			}
 		}
+	}
 
-		count := btoi(bCounters.Get(BucketHooks)) - len(keys)
+	if err := tx.Bucket(BucketPublic).Delete(indexKey(hook.Created, hook.Name)); err != nil {
+		return err
+	}
 
-		return bCounters.Put(BucketHooks, itob(count))
-	})
+	if bOwner := tx.Bucket(BucketOwners).Bucket([]byte(hook.Owner)); bOwner != nil {
+		return bOwner.Delete(indexKey(hook.Created, hook.Name))
+	}
+
+	return nil
 }
 
 // Requests returns hook requests by hook name
 func (b *BoltDB) Requests(hook string) ([]*models.Request, error) {
-	requests := make([]*models.Request, 0)
+	requests, _, err := b.RequestsPage(hook, "", 0, nil)
+
+	return requests, err
+}
+
+// RequestsPage returns a page of matched hook requests walking the index newest first
+func (b *BoltDB) RequestsPage(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+	p := newPage(limit, match)
 
 	err := b.db.View(func(tx *bolt.Tx) error {
 		bRequests := tx.Bucket(BucketReqs).Bucket([]byte(hook))
-		if bRequests == nil {
+		bIndex := tx.Bucket(BucketIndex).Bucket([]byte(hook))
+		if bRequests == nil || bIndex == nil {
 			return nil
 		}
 
-		return bRequests.ForEach(func(k, v []byte) error {
+		c := bIndex.Cursor()
+		k, _ := c.Last()
+
+		if before != "" {
+			created, name, err := parseCursor(before)
+			if err != nil {
+				return err
+			}
+
+			// the position is kept by the cursor, the request at it could be evicted already
+			if k, _ = c.Seek(indexKey(created, name)); k == nil {
+				k, _ = c.Last()
+			} else {
+				k, _ = c.Prev()
+			}
+		}
+
+		for ; k != nil; k, _ = c.Prev() {
+			v := bRequests.Get(k[8:])
+			if v == nil {
+				continue
+			}
+
 			request := &models.Request{}
 			if err := json.Unmarshal(v, &request); err != nil {
 				return err
 			}
 
-			requests = append(requests, request)
+			if !p.add(request) {
+				break
+			}
+		}
 
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return p.requests, p.next, nil
+}
+
+// NextRequest returns the oldest matched request walking the index forward from the given one
+func (b *BoltDB) NextRequest(hook string, after string, match func(req *models.Request) bool) (*models.Request, error) {
+	var next *models.Request
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bRequests := tx.Bucket(BucketReqs).Bucket([]byte(hook))
+		bIndex := tx.Bucket(BucketIndex).Bucket([]byte(hook))
+		if bRequests == nil || bIndex == nil {
 			return nil
-		})
+		}
+
+		v := bRequests.Get([]byte(after))
+		if v == nil {
+			return nil
+		}
+
+		c := bIndex.Cursor()
+
+		key := indexKey(createdOf(v), after)
+		if k, _ := c.Seek(key); !bytes.Equal(k, key) {
+			return nil
+		}
+
+		for k, _ := c.Next(); k != nil; k, _ = c.Next() {
+			v := bRequests.Get(k[8:])
+			if v == nil {
+				continue
+			}
+
+			request := &models.Request{}
+			if err := json.Unmarshal(v, &request); err != nil {
+				return err
+			}
+
+			if match == nil || match(request) {
+				next = request
+				break
+			}
+		}
+
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Slice(requests, func(i, j int) bool {
-		return requests[i].Created.After(requests[j].Created)
+	return next, nil
+}
+
+// Request returns hook request by name or nil if request is not exists
+func (b *BoltDB) Request(hook string, name string) (*models.Request, error) {
+	var request *models.Request
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bRequests := tx.Bucket(BucketReqs).Bucket([]byte(hook))
+		if bRequests == nil || bRequests.Get([]byte(name)) == nil {
+			return nil
+		}
+
+		return b.load(bRequests, name, &request)
 	})
 
-	return requests, nil
+	return request, err
 }
 
-// PutRequest save request model into storage
+// PutRequest save request model into storage.
+// The oldest requests of the hook are evicted when retention limits are exceeded
 func (b *BoltDB) PutRequest(hook string, req *models.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
 	return b.db.Update(func(tx *bolt.Tx) error {
 		bReqs, err := b.reqsBucket(tx, hook)
 		if err != nil {
 			return fmt.Errorf("can't get requests bucket for %s: %w", hook, err)
 		}
 
-		if err := b.save(bReqs, req.Name, req); err != nil {
-			return err
+		bIndex, err := b.indexBucket(tx, hook, bReqs)
+		if err != nil {
+			return fmt.Errorf("can't get index bucket for %s: %w", hook, err)
 		}
 
 		bCounters := tx.Bucket(BucketCounters)
-		count := btoi(bCounters.Get([]byte(hook))) + 1
+		count := btoi(bCounters.Get([]byte(hook)))
+
+		size, err := hookSize(tx, hook, bIndex)
+		if err != nil {
+			return err
+		}
+
+		if prev := bReqs.Get([]byte(req.Name)); prev != nil {
+			if err := bIndex.Delete(indexKey(createdOf(prev), req.Name)); err != nil {
+				return err
+			}
+
+			count--
+			size -= int64(len(prev))
+		}
+
+		if err := bReqs.Put([]byte(req.Name), data); err != nil {
+			return fmt.Errorf("failed to save key %s: %w", req.Name, err)
+		}
+
+		if err := bIndex.Put(indexKey(req.Created, req.Name), i64tob(int64(len(data)))); err != nil {
+			return err
+		}
+
+		count, size, err = b.evict(bReqs, bIndex, count+1, size+int64(len(data)))
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(BucketSizes).Put([]byte(hook), i64tob(size)); err != nil {
+			return err
+		}
 
 		return bCounters.Put([]byte(hook), itob(count))
 	})
 }
 
+// UpdateRequest save changes of existing request model into storage
+func (b *BoltDB) UpdateRequest(hook string, req *models.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bReqs := tx.Bucket(BucketReqs).Bucket([]byte(hook))
+		if bReqs == nil || bReqs.Get([]byte(req.Name)) == nil {
+			return fmt.Errorf("no value for %s", req.Name)
+		}
+
+		bIndex, err := b.indexBucket(tx, hook, bReqs)
+		if err != nil {
+			return fmt.Errorf("can't get index bucket for %s: %w", hook, err)
+		}
+
+		size, err := hookSize(tx, hook, bIndex)
+		if err != nil {
+			return err
+		}
+
+		size += int64(len(data) - len(bReqs.Get([]byte(req.Name))))
+
+		if err := bReqs.Put([]byte(req.Name), data); err != nil {
+			return fmt.Errorf("failed to save key %s: %w", req.Name, err)
+		}
+
+		// keep the sizes up to date, limits are applied on the next put
+		if err := bIndex.Put(indexKey(req.Created, req.Name), i64tob(int64(len(data)))); err != nil {
+			return err
+		}
+
+		return tx.Bucket(BucketSizes).Put([]byte(hook), i64tob(size))
+	})
+}
+
+// User returns user model by name or nil if user is not exists
+func (b *BoltDB) User(name string) (*models.User, error) {
+	var user *models.User
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bUsers := tx.Bucket(BucketUsers)
+		if bUsers.Get([]byte(name)) == nil {
+			return nil
+		}
+
+		return b.load(bUsers, name, &user)
+	})
+
+	return user, err
+}
+
+// PutUser save a new user model into storage, ErrUserExists is returned if the name is taken
+func (b *BoltDB) PutUser(user *models.User) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bUsers := tx.Bucket(BucketUsers)
+		if bUsers.Get([]byte(user.Name)) != nil {
+			return ErrUserExists
+		}
+
+		return b.save(bUsers, user.Name, user)
+	})
+}
+
+// Session returns session by id or nil if session is not exists or expired
+func (b *BoltDB) Session(id string) (*models.Session, error) {
+	var session *models.Session
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bSessions := tx.Bucket(BucketSessions)
+		if bSessions.Get([]byte(id)) == nil {
+			return nil
+		}
+
+		return b.load(bSessions, id, &session)
+	})
+	if err != nil || session == nil || session.Expired() {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// PutSession save session into storage
+func (b *BoltDB) PutSession(session *models.Session) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return b.save(tx.Bucket(BucketSessions), session.ID, session)
+	})
+}
+
+// DeleteSession deletes session by id
+func (b *BoltDB) DeleteSession(id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(BucketSessions).Delete([]byte(id))
+	})
+}
+
+// SweepSessions deletes expired sessions
+func (b *BoltDB) SweepSessions() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bSessions := tx.Bucket(BucketSessions)
+
+		var expired [][]byte
+
+		err := bSessions.ForEach(func(k, v []byte) error {
+			var session models.Session
+			if err := json.Unmarshal(v, &session); err != nil {
+				return fmt.Errorf("failed to unmarshal: %w", err)
+			}
+
+			if session.Expired() {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := bSessions.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 func (b *BoltDB) reqsBucket(tx *bolt.Tx, name string) (*bolt.Bucket, error) {
 	bkt, err := tx.Bucket(BucketReqs).CreateBucketIfNotExists([]byte(name))
 	if err != nil {
@@ -227,6 +782,68 @@ func (b *BoltDB) reqsBucket(tx *bolt.Tx, name string) (*bolt.Bucket, error) {
 	return bkt, nil
 }
 
+// indexBucket returns index bucket of the hook, requests stored before the index existed are indexed
+func (b *BoltDB) indexBucket(tx *bolt.Tx, name string, bReqs *bolt.Bucket) (*bolt.Bucket, error) {
+	if bkt := tx.Bucket(BucketIndex).Bucket([]byte(name)); bkt != nil {
+		return bkt, nil
+	}
+
+	bkt, err := tx.Bucket(BucketIndex).CreateBucket([]byte(name))
+	if err != nil {
+		return nil, err
+	}
+
+	err = bReqs.ForEach(func(k, v []byte) error {
+		return bkt.Put(indexKey(createdOf(v), string(k)), i64tob(int64(len(v))))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bkt, nil
+}
+
+// evict deletes the oldest requests while retention limits are exceeded, the newest one is kept.
+// It takes the number and the total size of requests of the hook and returns them after eviction
+func (b *BoltDB) evict(bReqs *bolt.Bucket, bIndex *bolt.Bucket, count int, size int64) (int, int64, error) {
+	if !b.opts.exceeds(count, size) {
+		return count, size, nil
+	}
+
+	c := bIndex.Cursor()
+	for k, v := c.First(); k != nil && count > 1 && b.opts.exceeds(count, size); k, v = c.First() {
+		if err := bReqs.Delete(k[8:]); err != nil {
+			return 0, 0, err
+		}
+
+		if err := c.Delete(); err != nil {
+			return 0, 0, err
+		}
+
+		count--
+		size -= btoi64(v)
+	}
+
+	return count, size, nil
+}
+
+// hookSize returns total size of requests of the hook,
+// it is summed up by the index for hooks stored before sizes were kept
+func hookSize(tx *bolt.Tx, hook string, bIndex *bolt.Bucket) (int64, error) {
+	if v := tx.Bucket(BucketSizes).Get([]byte(hook)); v != nil {
+		return btoi64(v), nil
+	}
+
+	var size int64
+
+	err := bIndex.ForEach(func(k, v []byte) error {
+		size += btoi64(v)
+		return nil
+	})
+
+	return size, err
+}
+
 // Expired returns list of keys that have ttl older than maxAge
 func (b *BoltDB) Expired(ttlName []byte, maxAge time.Duration) (keys [][]byte, err error) {
 	keys = [][]byte{}
@@ -237,8 +854,9 @@ func (b *BoltDB) Expired(ttlName []byte, maxAge time.Duration) (keys [][]byte, e
 
 		max := []byte(time.Now().Add(-maxAge).Format(time.RFC3339Nano))
 		for k, v := c.First(); k != nil && bytes.Compare(k, max) <= 0; k, v = c.Next() {
-			keys = append(keys, v)
-			ttlKeys = append(ttlKeys, k)
+			// bolt values are valid only within the transaction
+			keys = append(keys, append([]byte(nil), v...))
+			ttlKeys = append(ttlKeys, append([]byte(nil), k...))
 		}
 
 		return nil
@@ -329,6 +947,112 @@ func (b *BoltDB) load(bkt *bolt.Bucket, key string, res interface{}) error {
 	return nil
 }
 
+// indexKey returns key of the request or hook in the index, sorted by creation time
+func indexKey(created time.Time, name string) []byte {
+	ts := created.UnixNano()
+	if ts < 0 {
+		ts = 0
+	}
+
+	return append(i64tob(ts), name...)
+}
+
+// createdOf returns creation time of marshaled request
+func createdOf(data []byte) time.Time {
+	var req struct {
+		Created time.Time
+	}
+
+	_ = json.Unmarshal(data, &req)
+
+	return req.Created
+}
+
+// ttlKey returns bucket and key of the hook in the expiry indexes, owned hooks never expire and have no key
+func ttlKey(hook *models.Hook) ([]byte, []byte) {
+	if hook.Owner != "" {
+		return nil, nil
+	}
+
+	if hook.Expires.IsZero() {
+		return BucketTTL, []byte(hook.Created.Format(time.RFC3339Nano))
+	}
+
+	return BucketExpires, []byte(hook.Expires.Local().Format(time.RFC3339Nano))
+}
+
+// moveRequests moves requests of the hook to the new name with their paths and returns their total size.
+// The index is rebuilt as sizes of requests change with the paths
+func moveRequests(tx *bolt.Tx, name string, newName string) (int64, error) {
+	bIndex := tx.Bucket(BucketIndex)
+	if bIndex.Bucket([]byte(name)) != nil {
+		if err := bIndex.DeleteBucket([]byte(name)); err != nil {
+			return 0, err
+		}
+	}
+
+	bReqs := tx.Bucket(BucketReqs)
+
+	src := bReqs.Bucket([]byte(name))
+	if src == nil {
+		return 0, nil
+	}
+
+	dst, err := bReqs.CreateBucket([]byte(newName))
+	if err != nil {
+		return 0, err
+	}
+
+	dstIndex, err := bIndex.CreateBucket([]byte(newName))
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+
+	err = src.ForEach(func(k, v []byte) error {
+		var req *models.Request
+		if err := json.Unmarshal(v, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		req.Rename(name, newName)
+
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("can't marshal: %w", err)
+		}
+
+		if err := dst.Put(append([]byte(nil), k...), data); err != nil {
+			return err
+		}
+
+		size += int64(len(data))
+
+		return dstIndex.Put(indexKey(req.Created, string(k)), i64tob(int64(len(data))))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return size, bReqs.DeleteBucket([]byte(name))
+}
+
+func i64tob(i int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(i))
+
+	return b
+}
+
+func btoi64(b []byte) int64 {
+	if len(b) == 0 {
+		return 0
+	}
+
+	return int64(binary.BigEndian.Uint64(b))
+}
+
 func itob(i int) []byte {
 	b := make([]byte, 4)
 	binary.BigEndian.PutUint32(b, uint32(i))
@@ -34,6 +34,10 @@ func TestHook(t *testing.T) {
 
 	assert.Equal(t, exp, act)
 
+	act, err = s.Hook("unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, act)
+
 	assert.Equal(t, 1, mustCount(s, BucketHooks))
 	assert.Equal(t, 1, mustCount(s, BucketTTL))
 
@@ -46,6 +50,34 @@ func TestHook(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUpdateHook(t *testing.T) {
+	s := newTestBoltDB()
+	defer s.Close()
+
+	hook := models.NewHook(false)
+
+	err := s.UpdateHook(hook)
+	assert.Error(t, err) // not exists yet
+
+	err = s.PutHook(hook)
+	assert.NoError(t, err)
+
+	hook.Response = &models.Response{
+		Status: 201,
+		Body:   "created",
+	}
+
+	err = s.UpdateHook(hook)
+	assert.NoError(t, err)
+
+	act, err := s.Hook(hook.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, hook.Response, act.Response)
+
+	assert.Equal(t, 1, mustCount(s, BucketHooks))
+	assert.Equal(t, 1, mustCount(s, BucketTTL))
+}
+
 func TestRecentHooks(t *testing.T) {
 	s := newTestBoltDB()
 	defer s.Close()
@@ -64,6 +96,76 @@ func TestRecentHooks(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Len(t, hooks, 5)
+	assert.Equal(t, 10, mustCount(s, BucketPublic))
+
+	// hook made private is removed from the index
+	hooks[0].Private = true
+
+	err = s.UpdateHook(hooks[0])
+	assert.NoError(t, err)
+	assert.Equal(t, 9, mustCount(s, BucketPublic))
+}
+
+func TestRecentHooksLegacy(t *testing.T) {
+	path := tempfile()
+
+	s, err := NewBoltDB(path)
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		hook := models.NewHook(i%2 == 0)
+		hook.Name = fmt.Sprintf("hook%d", i)
+		hook.Created = now.Add(time.Duration(i) * time.Minute)
+
+		err = s.PutHook(hook)
+		assert.NoError(t, err)
+	}
+
+	// hooks stored before the index existed
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(BucketPublic)
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Close())
+
+	s, err = NewBoltDB(path)
+	assert.NoError(t, err)
+
+	defer s.Close()
+
+	hooks, err := s.RecentHooks(10)
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 2)
+	assert.Equal(t, "hook3", hooks[0].Name)
+	assert.Equal(t, "hook1", hooks[1].Name)
+}
+
+func BenchmarkRecentHooks(b *testing.B) {
+	s := newTestBoltDB()
+	defer s.Close()
+
+	s.db.NoSync = true // speed up seeding
+
+	now := time.Now()
+
+	for i := 0; i < 100000; i++ {
+		hook := models.NewHook(i%10 == 0)
+		hook.Created = now.Add(time.Duration(-i) * time.Second)
+
+		if err := s.PutHook(hook); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := s.RecentHooks(10); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
 
 func TestSweepHooks(t *testing.T) {
@@ -101,6 +203,7 @@ func TestSweepHooks(t *testing.T) {
 	assert.Equal(t, 7, mustCount(s, BucketHooks))
 	assert.Equal(t, 42, mustCount(s, BucketReqs))
 	assert.Equal(t, 7, mustCount(s, BucketTTL))
+	assert.Equal(t, 7, mustCount(s, BucketPublic))
 
 	err = s.SweepHooks(time.Duration(5) * time.Hour)
 	assert.NoError(t, err)
@@ -108,6 +211,7 @@ func TestSweepHooks(t *testing.T) {
 	assert.Equal(t, 5, mustCount(s, BucketHooks))
 	assert.Equal(t, 30, mustCount(s, BucketReqs))
 	assert.Equal(t, 5, mustCount(s, BucketTTL))
+	assert.Equal(t, 5, mustCount(s, BucketPublic))
 
 	err = s.db.View(func(tx *bolt.Tx) error {
 		count := btoi(tx.Bucket(BucketCounters).Get(BucketHooks))
@@ -128,13 +232,13 @@ func TestRequest(t *testing.T) {
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/foobar?foo=bar", nil)
-	getReq := models.NewRequest(req)
+	getReq := models.NewRequest(req, 1024)
 
 	err = s.PutRequest(hook.Name, getReq)
 	assert.NoError(t, err)
 
 	req = httptest.NewRequest(http.MethodPost, "/foobar", strings.NewReader(`{"foo": "bar"}`))
-	postReq := models.NewRequest(req)
+	postReq := models.NewRequest(req, 1024)
 
 	err = s.PutRequest(hook.Name, postReq)
 	assert.NoError(t, err)
@@ -143,9 +247,103 @@ func TestRequest(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, reqs, 2)
 
+	act, err := s.Request(hook.Name, getReq.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, getReq.Query, act.Query)
+
+	act, err = s.Request(hook.Name, "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, act)
+
 	assert.Equal(t, 3, mustCount(s, BucketReqs)) // 1 hook keys + 2 nested
 }
 
+func TestUpdateRequest(t *testing.T) {
+	s := newTestBoltDB()
+	defer s.Close()
+
+	hook := models.NewHook(false)
+
+	err := s.PutHook(hook)
+	assert.NoError(t, err)
+
+	req := &models.Request{
+		Name:    "foo",
+		Created: time.Now(),
+	}
+
+	err = s.UpdateRequest(hook.Name, req)
+	assert.Error(t, err) // not exists yet
+
+	err = s.PutRequest(hook.Name, req)
+	assert.NoError(t, err)
+
+	req.AddReplay(&models.Reply{
+		URL:    "http://localhost/foo",
+		Status: 200,
+	})
+
+	err = s.UpdateRequest(hook.Name, req)
+	assert.NoError(t, err)
+
+	act, err := s.Request(hook.Name, req.Name)
+	assert.NoError(t, err)
+	assert.Len(t, act.Replays, 1)
+	assert.Equal(t, 200, act.Replays[0].Status)
+
+	counters, err := s.Counters()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, counters[hook.Name])
+}
+
+func TestRetentionLegacy(t *testing.T) {
+	path := tempfile()
+
+	s, err := NewBoltDB(path)
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		req := &models.Request{
+			Name:    fmt.Sprintf("req%d", i),
+			Created: now.Add(time.Duration(i) * time.Second),
+		}
+
+		err = s.PutRequest("hook", req)
+		assert.NoError(t, err)
+	}
+
+	// requests stored before the index existed
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(BucketIndex).DeleteBucket([]byte("hook"))
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Close())
+
+	s, err = NewBoltDB(path, WithRetention(3, 0))
+	assert.NoError(t, err)
+
+	defer s.Close()
+
+	err = s.PutRequest("hook", &models.Request{Name: "req5", Created: now.Add(time.Minute)})
+	assert.NoError(t, err)
+
+	reqs, err := s.Requests("hook")
+	assert.NoError(t, err)
+	assert.Len(t, reqs, 3)
+	assert.Equal(t, "req5", reqs[0].Name)
+	assert.Equal(t, "req3", reqs[2].Name)
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, 3, btoi(tx.Bucket(BucketCounters).Get([]byte("hook"))))
+		assert.Equal(t, 3, tx.Bucket(BucketIndex).Bucket([]byte("hook")).Stats().KeyN)
+
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
 func TestExpired(t *testing.T) {
 	s := newTestBoltDB()
 	defer s.Close()
@@ -172,7 +370,7 @@ func TestExpired(t *testing.T) {
 }
 
 func newTestBoltDB() *BoltDB {
-	backend, err := New(tempfile())
+	backend, err := NewBoltDB(tempfile())
 	if err != nil {
 		panic(err)
 	}
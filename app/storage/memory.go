@@ -0,0 +1,618 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+// Memory is an in-memory storage, the data is lost on exit.
+// Models are kept marshaled to not share them with callers
+type Memory struct {
+	mu       sync.RWMutex
+	opts     Options
+	hooks    map[string][]byte
+	requests map[string]*memRequests
+	counters map[string]int
+	users    map[string][]byte
+	sessions map[string][]byte
+}
+
+type memRequest struct {
+	data    []byte
+	created time.Time
+}
+
+// memRequests are requests of a hook kept oldest first with their total size,
+// so the oldest ones are evicted without scanning all requests
+type memRequests struct {
+	byName map[string]memRequest
+	names  []string // sorted by creation time and name
+	size   int64
+}
+
+// NewMemory returns an empty in-memory storage
+func NewMemory(options ...Option) *Memory {
+	return &Memory{
+		opts:     newOptions(options),
+		hooks:    make(map[string][]byte),
+		requests: make(map[string]*memRequests),
+		counters: make(map[string]int),
+		users:    make(map[string][]byte),
+		sessions: make(map[string][]byte),
+	}
+}
+
+// Close releases all storage resources
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Hook returns hook model by name or nil if hook is not exists
+func (m *Memory) Hook(name string) (*models.Hook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.hooks[name]
+	if !ok {
+		return nil, nil
+	}
+
+	var hook *models.Hook
+	if err := json.Unmarshal(data, &hook); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return hook, nil
+}
+
+// PutHook save a new hook model into storage, ErrHookExists is returned if the name is taken
+func (m *Memory) PutHook(hook *models.Hook) error {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.hooks[hook.Name]; ok {
+		return ErrHookExists
+	}
+
+	m.hooks[hook.Name] = data
+	m.counters[string(BucketHooks)]++
+
+	return nil
+}
+
+// UpdateHook save changes of existing hook model into storage
+func (m *Memory) UpdateHook(hook *models.Hook) error {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.hooks[hook.Name]; !ok {
+		return fmt.Errorf("no value for %s", hook.Name)
+	}
+
+	m.hooks[hook.Name] = data
+
+	return nil
+}
+
+// RenameHook renames hook keeping its requests, ErrHookExists is returned if the new name is taken
+func (m *Memory) RenameHook(name string, newName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.hooks[name]
+	if !ok {
+		return fmt.Errorf("no value for %s", name)
+	}
+
+	if _, ok := m.hooks[newName]; ok {
+		return ErrHookExists
+	}
+
+	var hook *models.Hook
+	if err := json.Unmarshal(data, &hook); err != nil {
+		return fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	hook.Name = newName
+
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	if requests, ok := m.requests[name]; ok {
+		renamed, err := requests.rename(name, newName)
+		if err != nil {
+			return err
+		}
+
+		m.requests[newName] = renamed
+		delete(m.requests, name)
+	}
+
+	m.hooks[newName] = data
+	delete(m.hooks, name)
+
+	if count, ok := m.counters[name]; ok {
+		m.counters[newName] = count
+		delete(m.counters, name)
+	}
+
+	return nil
+}
+
+// DeleteHook deletes hook with its requests
+func (m *Memory) DeleteHook(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deleteHook(name)
+
+	return nil
+}
+
+// RecentHooks returns recent public hooks
+func (m *Memory) RecentHooks(max int) ([]*models.Hook, error) {
+	hooks, err := m.allHooks()
+	if err != nil {
+		return nil, err
+	}
+
+	recent := make([]*models.Hook, 0, max)
+
+	for _, hook := range hooks {
+		if !hook.Private {
+			recent = append(recent, hook)
+		}
+	}
+
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].Created.After(recent[j].Created)
+	})
+
+	if len(recent) > max {
+		return recent[0:max], nil
+	}
+
+	return recent, nil
+}
+
+// UserHooks returns hooks owned by the user
+func (m *Memory) UserHooks(user string) ([]*models.Hook, error) {
+	hooks, err := m.allHooks()
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make([]*models.Hook, 0)
+
+	for _, hook := range hooks {
+		if hook.Owner == user {
+			owned = append(owned, hook)
+		}
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].Created.After(owned[j].Created)
+	})
+
+	return owned, nil
+}
+
+// SweepHooks deletes expired hooks with their requests
+func (m *Memory) SweepHooks(maxAge time.Duration) error {
+	now := time.Now()
+
+	// hooks are checked and deleted under the same lock, so concurrent changes are not lost
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, data := range m.hooks {
+		var hook models.Hook
+		if err := json.Unmarshal(data, &hook); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		if hook.Owner != "" || hook.ExpiresAt(maxAge).After(now) {
+			continue
+		}
+
+		m.deleteHook(name)
+	}
+
+	return nil
+}
+
+// Requests returns hook requests by hook name
+func (m *Memory) Requests(hook string) ([]*models.Request, error) {
+	requests, _, err := m.RequestsPage(hook, "", 0, nil)
+
+	return requests, err
+}
+
+// RequestsPage returns a page of matched hook requests, newest first
+func (m *Memory) RequestsPage(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p := newPage(limit, match)
+
+	var (
+		created time.Time
+		name    string
+	)
+
+	if before != "" {
+		var err error
+		if created, name, err = parseCursor(before); err != nil {
+			return nil, "", err
+		}
+	}
+
+	requests := m.requests[hook]
+	if requests == nil {
+		return p.requests, p.next, nil
+	}
+
+	start := len(requests.names) - 1
+	if before != "" {
+		start = requests.search(created, name) - 1
+	}
+
+	for i := start; i >= 0; i-- {
+		request := &models.Request{}
+		if err := json.Unmarshal(requests.byName[requests.names[i]].data, &request); err != nil {
+			return nil, "", err
+		}
+
+		if !p.add(request) {
+			break
+		}
+	}
+
+	return p.requests, p.next, nil
+}
+
+// NextRequest returns the oldest matched hook request stored after the given one
+func (m *Memory) NextRequest(hook string, after string, match func(req *models.Request) bool) (*models.Request, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	requests := m.requests[hook]
+
+	stored, ok := requests.get(after)
+	if !ok {
+		return nil, nil
+	}
+
+	for i := requests.search(stored.created, after) + 1; i < len(requests.names); i++ {
+		request := &models.Request{}
+		if err := json.Unmarshal(requests.byName[requests.names[i]].data, &request); err != nil {
+			return nil, err
+		}
+
+		if match == nil || match(request) {
+			return request, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Request returns hook request by name or nil if request is not exists
+func (m *Memory) Request(hook string, name string) (*models.Request, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.requests[hook].get(name)
+	if !ok {
+		return nil, nil
+	}
+
+	var request *models.Request
+	if err := json.Unmarshal(stored.data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return request, nil
+}
+
+// PutRequest save request model into storage.
+// The oldest requests of the hook are evicted when retention limits are exceeded
+func (m *Memory) PutRequest(hook string, req *models.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests, ok := m.requests[hook]
+	if !ok {
+		requests = &memRequests{byName: make(map[string]memRequest)}
+		m.requests[hook] = requests
+	}
+
+	if requests.put(req.Name, memRequest{data: data, created: req.Created}) {
+		m.counters[hook]++
+	}
+
+	m.counters[hook] -= requests.evict(m.opts)
+
+	return nil
+}
+
+// UpdateRequest save changes of existing request model into storage
+func (m *Memory) UpdateRequest(hook string, req *models.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.requests[hook].get(req.Name)
+	if !ok {
+		return fmt.Errorf("no value for %s", req.Name)
+	}
+
+	// the creation time and so the position are kept
+	m.requests[hook].size += int64(len(data) - len(stored.data))
+	stored.data = data
+	m.requests[hook].byName[req.Name] = stored
+
+	return nil
+}
+
+// User returns user model by name or nil if user is not exists
+func (m *Memory) User(name string) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.users[name]
+	if !ok {
+		return nil, nil
+	}
+
+	var user *models.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return user, nil
+}
+
+// PutUser save a new user model into storage, ErrUserExists is returned if the name is taken
+func (m *Memory) PutUser(user *models.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[user.Name]; ok {
+		return ErrUserExists
+	}
+
+	m.users[user.Name] = data
+
+	return nil
+}
+
+// Session returns session by id or nil if session is not exists or expired
+func (m *Memory) Session(id string) (*models.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	var session *models.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	if session.Expired() {
+		return nil, nil
+	}
+
+	return session, nil
+}
+
+// PutSession save session into storage
+func (m *Memory) PutSession(session *models.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[session.ID] = data
+
+	return nil
+}
+
+// DeleteSession deletes session by id
+func (m *Memory) DeleteSession(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+
+	return nil
+}
+
+// SweepSessions deletes expired sessions
+func (m *Memory) SweepSessions() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, data := range m.sessions {
+		var session models.Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		if session.Expired() {
+			delete(m.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+// Counters returns list of counters
+func (m *Memory) Counters() (map[string]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counters := make(map[string]int, len(m.counters))
+	for k, v := range m.counters {
+		counters[k] = v
+	}
+
+	return counters, nil
+}
+
+// deleteHook deletes hook with its requests, should run under write lock
+func (m *Memory) deleteHook(name string) {
+	if _, ok := m.hooks[name]; !ok {
+		return
+	}
+
+	delete(m.hooks, name)
+	delete(m.requests, name)
+	delete(m.counters, name)
+
+	m.counters[string(BucketHooks)]--
+}
+
+// get returns the stored request by name
+func (rs *memRequests) get(name string) (memRequest, bool) {
+	if rs == nil {
+		return memRequest{}, false
+	}
+
+	stored, ok := rs.byName[name]
+
+	return stored, ok
+}
+
+// search returns position of the request in the names sorted by creation time
+func (rs *memRequests) search(created time.Time, name string) int {
+	return sort.Search(len(rs.names), func(i int) bool {
+		other := rs.byName[rs.names[i]]
+		if other.created.Equal(created) {
+			return rs.names[i] >= name
+		}
+
+		return other.created.After(created)
+	})
+}
+
+// put adds or replaces the request, it reports whether the request is added
+func (rs *memRequests) put(name string, stored memRequest) bool {
+	prev, exists := rs.byName[name]
+	if exists {
+		i := rs.search(prev.created, name)
+		rs.names = append(rs.names[:i], rs.names[i+1:]...)
+		rs.size -= int64(len(prev.data))
+	}
+
+	rs.byName[name] = stored
+	rs.size += int64(len(stored.data))
+
+	i := rs.search(stored.created, name)
+	rs.names = append(rs.names, "")
+	copy(rs.names[i+1:], rs.names[i:])
+	rs.names[i] = name
+
+	return !exists
+}
+
+// evict deletes the oldest requests while retention limits are exceeded, the newest one is kept.
+// It returns the number of evicted requests
+func (rs *memRequests) evict(opts Options) int {
+	evicted := 0
+
+	for len(rs.names) > 1 && opts.exceeds(len(rs.names), rs.size) {
+		name := rs.names[0]
+
+		rs.size -= int64(len(rs.byName[name].data))
+		delete(rs.byName, name)
+
+		rs.names[0] = ""
+		rs.names = rs.names[1:]
+		evicted++
+	}
+
+	return evicted
+}
+
+// rename returns copies of the requests with paths moved under the new name of the hook
+func (rs *memRequests) rename(name string, newName string) (*memRequests, error) {
+	renamed := &memRequests{
+		byName: make(map[string]memRequest, len(rs.byName)),
+		names:  append([]string(nil), rs.names...),
+	}
+
+	for key, stored := range rs.byName {
+		var req *models.Request
+		if err := json.Unmarshal(stored.data, &req); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		req.Rename(name, newName)
+
+		data, err := json.Marshal(req)
+		if err != nil {
+			return nil, fmt.Errorf("can't marshal: %w", err)
+		}
+
+		renamed.byName[key] = memRequest{data: data, created: stored.created}
+		renamed.size += int64(len(data))
+	}
+
+	return renamed, nil
+}
+
+func (m *Memory) allHooks() ([]*models.Hook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	hooks := make([]*models.Hook, 0, len(m.hooks))
+
+	for _, data := range m.hooks {
+		var hook models.Hook
+		if err := json.Unmarshal(data, &hook); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		hooks = append(hooks, &hook)
+	}
+
+	return hooks, nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+// Options is storage options
+type Options struct {
+	MaxRequests int   // max number of stored requests per hook, zero is unlimited
+	MaxBytes    int64 // max total size of stored requests per hook, zero is unlimited
+}
+
+// Option function type
+type Option func(o *Options)
+
+// WithRetention setting up limits of stored requests per hook, the oldest requests are evicted
+func WithRetention(maxRequests int, maxBytes int64) Option {
+	return func(o *Options) {
+		o.MaxRequests = maxRequests
+		o.MaxBytes = maxBytes
+	}
+}
+
+func newOptions(options []Option) Options {
+	var opts Options
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	return opts
+}
+
+// limited reports whether any retention limit is set
+func (o Options) limited() bool {
+	return o.MaxRequests > 0 || o.MaxBytes > 0
+}
+
+// exceeds reports whether stored requests of a hook exceed the retention limits
+func (o Options) exceeds(count int, size int64) bool {
+	return (o.MaxRequests > 0 && count > o.MaxRequests) || (o.MaxBytes > 0 && size > o.MaxBytes)
+}
@@ -0,0 +1,766 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // pure go sqlite driver
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+// SQLite is a storage over SQLite database.
+// Models are stored as JSON along with the columns used for lookups
+type SQLite struct {
+	db   *sql.DB
+	opts Options
+}
+
+// sqliteMigrations are schema changes by version, applied versions are tracked by user_version pragma
+var sqliteMigrations = [][]string{
+	{
+		`CREATE TABLE IF NOT EXISTS hooks (
+			name    TEXT PRIMARY KEY,
+			private INTEGER NOT NULL,
+			created INTEGER NOT NULL,
+			data    TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS hooks_created ON hooks (created)`,
+		`CREATE TABLE IF NOT EXISTS requests (
+			hook    TEXT NOT NULL,
+			name    TEXT NOT NULL,
+			created INTEGER NOT NULL,
+			data    TEXT NOT NULL,
+			PRIMARY KEY (hook, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS counters (
+			name  TEXT PRIMARY KEY,
+			value INTEGER NOT NULL
+		)`,
+	},
+	{
+		`ALTER TABLE hooks ADD COLUMN expires INTEGER NOT NULL DEFAULT 0`, // zero for hooks without own expiry
+		`CREATE INDEX IF NOT EXISTS hooks_expires ON hooks (expires)`,
+	},
+	{
+		`ALTER TABLE requests ADD COLUMN size INTEGER NOT NULL DEFAULT 0`,
+		`UPDATE requests SET size = length(data)`,
+		`CREATE INDEX IF NOT EXISTS requests_created ON requests (hook, created)`,
+	},
+	{
+		`ALTER TABLE hooks ADD COLUMN owner TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS hooks_owner ON hooks (owner, created)`,
+		`CREATE TABLE IF NOT EXISTS users (
+			name TEXT PRIMARY KEY,
+			data TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS sessions (
+			id      TEXT PRIMARY KEY,
+			expires INTEGER NOT NULL,
+			data    TEXT NOT NULL
+		)`,
+	},
+}
+
+// NewSQLite returns a storage over SQLite database file
+func NewSQLite(path string, options ...Option) (*SQLite, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite allows a single writer, serialize access instead of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	s := &SQLite{
+		db:   db,
+		opts: newOptions(options),
+	}
+
+	if err := s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	return s, nil
+}
+
+// Close releases all database resources
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// Hook returns hook model by name or nil if hook is not exists
+func (s *SQLite) Hook(name string) (*models.Hook, error) {
+	var data []byte
+
+	err := s.db.QueryRow(`SELECT data FROM hooks WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var hook *models.Hook
+	if err := json.Unmarshal(data, &hook); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return hook, nil
+}
+
+// PutHook save a new hook model into storage, ErrHookExists is returned if the name is taken
+func (s *SQLite) PutHook(hook *models.Hook) error {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	return s.tx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`INSERT OR IGNORE INTO hooks (name, private, created, expires, owner, data) VALUES (?, ?, ?, ?, ?, ?)`,
+			hook.Name, hook.Private, hook.Created.UnixNano(), unixNano(hook.Expires), hook.Owner, data)
+		if err != nil {
+			return err
+		}
+
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrHookExists
+		}
+
+		return incCounter(tx, string(BucketHooks), 1)
+	})
+}
+
+// UpdateHook save changes of existing hook model into storage
+func (s *SQLite) UpdateHook(hook *models.Hook) error {
+	data, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	res, err := s.db.Exec(`UPDATE hooks SET private = ?, expires = ?, owner = ?, data = ? WHERE name = ?`,
+		hook.Private, unixNano(hook.Expires), hook.Owner, data, hook.Name)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no value for %s", hook.Name)
+	}
+
+	return nil
+}
+
+// RenameHook renames hook keeping its requests, ErrHookExists is returned if the new name is taken
+func (s *SQLite) RenameHook(name string, newName string) error {
+	return s.tx(func(tx *sql.Tx) error {
+		var data []byte
+
+		err := tx.QueryRow(`SELECT data FROM hooks WHERE name = ?`, name).Scan(&data)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no value for %s", name)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var exists int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM hooks WHERE name = ?`, newName).Scan(&exists); err != nil {
+			return err
+		}
+
+		if exists > 0 {
+			return ErrHookExists
+		}
+
+		var hook *models.Hook
+		if err := json.Unmarshal(data, &hook); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		hook.Name = newName
+
+		if data, err = json.Marshal(hook); err != nil {
+			return fmt.Errorf("can't marshal: %w", err)
+		}
+
+		if _, err := tx.Exec(`UPDATE hooks SET name = ?, data = ? WHERE name = ?`, newName, data, name); err != nil {
+			return err
+		}
+
+		for _, query := range []string{
+			`UPDATE requests SET hook = ? WHERE hook = ?`,
+			`UPDATE counters SET name = ? WHERE name = ?`,
+		} {
+			if _, err := tx.Exec(query, newName, name); err != nil {
+				return err
+			}
+		}
+
+		return s.renameRequests(tx, name, newName)
+	})
+}
+
+// renameRequests moves paths of requests of the renamed hook under the new name
+func (s *SQLite) renameRequests(tx *sql.Tx, name string, newName string) error {
+	rows, err := tx.Query(`SELECT data FROM requests WHERE hook = ?`, newName)
+	if err != nil {
+		return err
+	}
+
+	requests := make([]*models.Request, 0)
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			_ = rows.Close()
+			return err
+		}
+
+		var req *models.Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		requests = append(requests, req)
+	}
+
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for _, req := range requests {
+		req.Rename(name, newName)
+
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("can't marshal: %w", err)
+		}
+
+		_, err = tx.Exec(`UPDATE requests SET size = ?, data = ? WHERE hook = ? AND name = ?`, len(data), data, newName, req.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteHook deletes hook with its requests
+func (s *SQLite) DeleteHook(name string) error {
+	return s.tx(func(tx *sql.Tx) error {
+		for _, query := range []string{
+			`DELETE FROM requests WHERE hook = ?`,
+			`DELETE FROM counters WHERE name = ?`,
+		} {
+			if _, err := tx.Exec(query, name); err != nil {
+				return err
+			}
+		}
+
+		res, err := tx.Exec(`DELETE FROM hooks WHERE name = ?`, name)
+		if err != nil {
+			return err
+		}
+
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+
+		return incCounter(tx, string(BucketHooks), -1)
+	})
+}
+
+// RecentHooks returns recent public hooks
+func (s *SQLite) RecentHooks(max int) ([]*models.Hook, error) {
+	rows, err := s.db.Query(`SELECT data FROM hooks WHERE private = 0 ORDER BY created DESC LIMIT ?`, max)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	hooks := make([]*models.Hook, 0, max)
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var hook models.Hook
+		if err := json.Unmarshal(data, &hook); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		hooks = append(hooks, &hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+// UserHooks returns hooks owned by the user
+func (s *SQLite) UserHooks(user string) ([]*models.Hook, error) {
+	rows, err := s.db.Query(`SELECT data FROM hooks WHERE owner = ? ORDER BY created DESC`, user)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	hooks := make([]*models.Hook, 0)
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var hook models.Hook
+		if err := json.Unmarshal(data, &hook); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		hooks = append(hooks, &hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+// SweepHooks deletes expired hooks with their requests
+func (s *SQLite) SweepHooks(maxAge time.Duration) error {
+	now := time.Now()
+	deadline := now.Add(-maxAge).UnixNano()
+
+	const expired = `SELECT name FROM hooks WHERE owner = '' AND
+		((expires = 0 AND created <= ?) OR (expires != 0 AND expires <= ?))`
+
+	return s.tx(func(tx *sql.Tx) error {
+		queries := []string{
+			`DELETE FROM requests WHERE hook IN (` + expired + `)`,
+			`DELETE FROM counters WHERE name IN (` + expired + `)`,
+		}
+
+		for _, query := range queries {
+			if _, err := tx.Exec(query, deadline, now.UnixNano()); err != nil {
+				return err
+			}
+		}
+
+		res, err := tx.Exec(`DELETE FROM hooks WHERE name IN (`+expired+`)`, deadline, now.UnixNano())
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil || n == 0 {
+			return err
+		}
+
+		return incCounter(tx, string(BucketHooks), -int(n))
+	})
+}
+
+// Requests returns hook requests by hook name
+func (s *SQLite) Requests(hook string) ([]*models.Request, error) {
+	requests, _, err := s.RequestsPage(hook, "", 0, nil)
+
+	return requests, err
+}
+
+// RequestsPage returns a page of matched hook requests, newest first
+func (s *SQLite) RequestsPage(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error) {
+	query := `SELECT data FROM requests WHERE hook = ? ORDER BY created DESC, name DESC`
+	args := []interface{}{hook}
+
+	if before != "" {
+		created, name, err := parseCursor(before)
+		if err != nil {
+			return nil, "", err
+		}
+
+		query = `SELECT data FROM requests WHERE hook = ? AND (created < ? OR (created = ? AND name < ?))
+			ORDER BY created DESC, name DESC`
+		args = append(args, created.UnixNano(), created.UnixNano(), name)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	p := newPage(limit, match)
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, "", err
+		}
+
+		request := &models.Request{}
+		if err := json.Unmarshal(data, &request); err != nil {
+			return nil, "", err
+		}
+
+		if !p.add(request) {
+			break
+		}
+	}
+
+	return p.requests, p.next, rows.Err()
+}
+
+// NextRequest returns the oldest matched hook request stored after the given one
+func (s *SQLite) NextRequest(hook string, after string, match func(req *models.Request) bool) (*models.Request, error) {
+	var created int64
+
+	err := s.db.QueryRow(`SELECT created FROM requests WHERE hook = ? AND name = ?`, hook, after).Scan(&created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT data FROM requests WHERE hook = ? AND (created > ? OR (created = ? AND name > ?))
+		ORDER BY created, name`, hook, created, created, after)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		request := &models.Request{}
+		if err := json.Unmarshal(data, &request); err != nil {
+			return nil, err
+		}
+
+		if match == nil || match(request) {
+			return request, nil
+		}
+	}
+
+	return nil, rows.Err()
+}
+
+// Request returns hook request by name or nil if request is not exists
+func (s *SQLite) Request(hook string, name string) (*models.Request, error) {
+	var data []byte
+
+	err := s.db.QueryRow(`SELECT data FROM requests WHERE hook = ? AND name = ?`, hook, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var request *models.Request
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return request, nil
+}
+
+// PutRequest save request model into storage.
+// The oldest requests of the hook are evicted when retention limits are exceeded
+func (s *SQLite) PutRequest(hook string, req *models.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	return s.tx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`INSERT OR IGNORE INTO requests (hook, name, created, size, data) VALUES (?, ?, ?, ?, ?)`,
+			hook, req.Name, req.Created.UnixNano(), len(data), data)
+		if err != nil {
+			return err
+		}
+
+		added := 1
+
+		if n, _ := res.RowsAffected(); n == 0 {
+			_, err = tx.Exec(`UPDATE requests SET created = ?, size = ?, data = ? WHERE hook = ? AND name = ?`,
+				req.Created.UnixNano(), len(data), data, hook, req.Name)
+			if err != nil {
+				return err
+			}
+
+			added = 0
+		}
+
+		evicted, err := s.evict(tx, hook)
+		if err != nil {
+			return err
+		}
+
+		if delta := added - evicted; delta != 0 {
+			return incCounter(tx, hook, delta)
+		}
+
+		return nil
+	})
+}
+
+// UpdateRequest save changes of existing request model into storage
+func (s *SQLite) UpdateRequest(hook string, req *models.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	res, err := s.db.Exec(`UPDATE requests SET size = ?, data = ? WHERE hook = ? AND name = ?`, len(data), data, hook, req.Name)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no value for %s", req.Name)
+	}
+
+	return nil
+}
+
+// User returns user model by name or nil if user is not exists
+func (s *SQLite) User(name string) (*models.User, error) {
+	var data []byte
+
+	err := s.db.QueryRow(`SELECT data FROM users WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return user, nil
+}
+
+// PutUser save a new user model into storage, ErrUserExists is returned if the name is taken
+func (s *SQLite) PutUser(user *models.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	res, err := s.db.Exec(`INSERT OR IGNORE INTO users (name, data) VALUES (?, ?)`, user.Name, data)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserExists
+	}
+
+	return nil
+}
+
+// Session returns session by id or nil if session is not exists or expired
+func (s *SQLite) Session(id string) (*models.Session, error) {
+	var data []byte
+
+	err := s.db.QueryRow(`SELECT data FROM sessions WHERE id = ? AND expires > ?`, id, time.Now().UnixNano()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var session *models.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return session, nil
+}
+
+// PutSession save session into storage
+func (s *SQLite) PutSession(session *models.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("can't marshal: %w", err)
+	}
+
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO sessions (id, expires, data) VALUES (?, ?, ?)`,
+		session.ID, session.Expires.UnixNano(), data)
+
+	return err
+}
+
+// DeleteSession deletes session by id
+func (s *SQLite) DeleteSession(id string) error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+
+	return err
+}
+
+// SweepSessions deletes expired sessions
+func (s *SQLite) SweepSessions() error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE expires <= ?`, time.Now().UnixNano())
+
+	return err
+}
+
+// Counters returns list of counters
+func (s *SQLite) Counters() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT name, value FROM counters`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	counters := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			name  string
+			value int
+		)
+
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+
+		counters[name] = value
+	}
+
+	return counters, rows.Err()
+}
+
+// evict deletes the oldest requests of the hook while retention limits are exceeded, the newest one is kept
+func (s *SQLite) evict(tx *sql.Tx, hook string) (int, error) {
+	if !s.opts.limited() {
+		return 0, nil
+	}
+
+	var (
+		count int
+		size  int64
+	)
+
+	err := tx.QueryRow(`SELECT COUNT(*), COALESCE(SUM(size), 0) FROM requests WHERE hook = ?`, hook).Scan(&count, &size)
+	if err != nil || !s.opts.exceeds(count, size) {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`SELECT name, size FROM requests WHERE hook = ? ORDER BY created, name`, hook)
+	if err != nil {
+		return 0, err
+	}
+
+	names := make([]string, 0)
+
+	for rows.Next() && count-len(names) > 1 && s.opts.exceeds(count-len(names), size) {
+		var (
+			name string
+			n    int64
+		)
+
+		if err := rows.Scan(&name, &n); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+
+		names = append(names, name)
+		size -= n
+	}
+
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	for _, name := range names {
+		if _, err := tx.Exec(`DELETE FROM requests WHERE hook = ? AND name = ?`, hook, name); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(names), nil
+}
+
+// migrate applies schema changes which are not applied yet
+func (s *SQLite) migrate() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		queries := sqliteMigrations[version]
+
+		err := s.tx(func(tx *sql.Tx) error {
+			for _, query := range queries {
+				if _, err := tx.Exec(query); err != nil {
+					return err
+				}
+			}
+
+			_, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1))
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tx runs fn in a transaction, the transaction is committed if fn succeeds
+func (s *SQLite) tx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// unixNano returns t as unix nanoseconds, zero time is stored as 0
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+func incCounter(tx *sql.Tx, name string, delta int) error {
+	_, err := tx.Exec(`INSERT INTO counters (name, value) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET value = value + excluded.value`, name, delta)
+
+	return err
+}
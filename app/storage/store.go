@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+// Store is a storage of hooks and their requests
+type Store interface {
+	// Hook returns hook model by name or nil if hook is not exists
+	Hook(name string) (*models.Hook, error)
+	// PutHook save a new hook model into storage, ErrHookExists is returned if the name is taken
+	PutHook(hook *models.Hook) error
+	// UpdateHook save changes of existing hook model into storage
+	UpdateHook(hook *models.Hook) error
+	// RenameHook renames hook keeping its requests, ErrHookExists is returned if the new name is taken
+	RenameHook(name string, newName string) error
+	// DeleteHook deletes hook with its requests
+	DeleteHook(name string) error
+	// RecentHooks returns recent public hooks, newest first
+	RecentHooks(max int) ([]*models.Hook, error)
+	// UserHooks returns hooks owned by the user, newest first
+	UserHooks(user string) ([]*models.Hook, error)
+	// SweepHooks deletes expired hooks with their requests,
+	// hooks without own expiry are expired after maxAge since creation, owned hooks never expire
+	SweepHooks(maxAge time.Duration) error
+	// Requests returns hook requests by hook name, newest first
+	Requests(hook string) ([]*models.Request, error)
+	// RequestsPage returns up to limit matched hook requests, newest first, starting after the before cursor.
+	// Zero limit and nil match are unlimited. The cursor of the next page is empty on the last page,
+	// ErrInvalidCursor is returned for malformed cursors
+	RequestsPage(hook string, before string, limit int, match func(req *models.Request) bool) ([]*models.Request, string, error)
+	// NextRequest returns the oldest matched hook request stored after the request named after,
+	// nil is returned if there is no such request. Nil match matches any request
+	NextRequest(hook string, after string, match func(req *models.Request) bool) (*models.Request, error)
+	// Request returns hook request by name or nil if request is not exists
+	Request(hook string, name string) (*models.Request, error)
+	// PutRequest save request model into storage
+	PutRequest(hook string, req *models.Request) error
+	// UpdateRequest save changes of existing request model into storage
+	UpdateRequest(hook string, req *models.Request) error
+	// User returns user model by name or nil if user is not exists
+	User(name string) (*models.User, error)
+	// PutUser save a new user model into storage, ErrUserExists is returned if the name is taken
+	PutUser(user *models.User) error
+	// Session returns session by id or nil if session is not exists or expired
+	Session(id string) (*models.Session, error)
+	// PutSession save session into storage
+	PutSession(session *models.Session) error
+	// DeleteSession deletes session by id
+	DeleteSession(id string) error
+	// SweepSessions deletes expired sessions
+	SweepSessions() error
+	// Counters returns number of hooks by "hooks" key and number of requests by hook names
+	Counters() (map[string]int, error)
+	// Close releases all storage resources
+	Close() error
+}
+
+var (
+	// ErrHookExists is returned when the hook name is already taken
+	ErrHookExists = errors.New("hook already exists")
+	// ErrUserExists is returned when the user name is already taken
+	ErrUserExists = errors.New("user already exists")
+	// ErrInvalidCursor is returned for malformed cursors of requests pages
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+var (
+	_ Store = (*BoltDB)(nil)
+	_ Store = (*Memory)(nil)
+	_ Store = (*SQLite)(nil)
+)
+
+// page collects matched requests walked newest first
+type page struct {
+	limit    int
+	match    func(req *models.Request) bool
+	requests []*models.Request
+	next     string
+}
+
+func newPage(limit int, match func(req *models.Request) bool) *page {
+	return &page{
+		limit:    limit,
+		match:    match,
+		requests: make([]*models.Request, 0),
+	}
+}
+
+// add appends request if it is matched, it returns false when the page is full
+func (p *page) add(req *models.Request) bool {
+	if p.match != nil && !p.match(req) {
+		return true
+	}
+
+	if p.limit > 0 && len(p.requests) == p.limit {
+		p.next = Cursor(p.requests[len(p.requests)-1])
+		return false
+	}
+
+	p.requests = append(p.requests, req)
+
+	return true
+}
+
+// Cursor returns position of the request among requests of the hook ordered by creation time.
+// The position stays valid when the request itself is evicted
+func Cursor(req *models.Request) string {
+	return strconv.FormatInt(req.Created.UnixNano(), 36) + "." + req.Name
+}
+
+// parseCursor returns creation time and name of the request at the cursor position
+func parseCursor(cursor string) (time.Time, string, error) {
+	parts := strings.SplitN(cursor, ".", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	ts, err := strconv.ParseInt(parts[0], 36, 64)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	return time.Unix(0, ts), parts[1], nil
+}
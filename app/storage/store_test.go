@@ -0,0 +1,795 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+// testBackends returns constructors of every storage backend
+func testBackends() map[string]func(options ...Option) Store {
+	return map[string]func(options ...Option) Store{
+		"bolt": func(options ...Option) Store {
+			s, err := NewBoltDB(tempfile(), options...)
+			if err != nil {
+				panic(err)
+			}
+
+			return s
+		},
+		"memory": func(options ...Option) Store {
+			return NewMemory(options...)
+		},
+		"sqlite": func(options ...Option) Store {
+			s, err := NewSQLite(tempfile(), options...)
+			if err != nil {
+				panic(err)
+			}
+
+			return s
+		},
+	}
+}
+
+// TestStoreConformance runs the same suite against every storage backend
+func TestStoreConformance(t *testing.T) {
+	suite := map[string]func(t *testing.T, s Store){
+		"hook":           testStoreHook,
+		"recent hooks":   testStoreRecentHooks,
+		"sweep hooks":    testStoreSweepHooks,
+		"sweep expires":  testStoreSweepExpires,
+		"sweep deleted":  testStoreSweepDeleted,
+		"requests":       testStoreRequests,
+		"update request": testStoreUpdateRequest,
+		"requests page":  testStoreRequestsPage,
+		"next request":   testStoreNextRequest,
+		"hook conflict":  testStoreHookConflict,
+		"owned hooks":    testStoreOwnedHooks,
+		"rename hook":    testStoreRenameHook,
+		"delete hook":    testStoreDeleteHook,
+		"users":          testStoreUsers,
+		"sessions":       testStoreSessions,
+	}
+
+	for backend, newStore := range testBackends() {
+		for name, test := range suite {
+			newStore, test := newStore, test
+
+			t.Run(backend+"/"+name, func(t *testing.T) {
+				s := newStore()
+				defer s.Close()
+
+				test(t, s)
+			})
+		}
+	}
+}
+
+// TestStoreRequestsPageEvicted checks paging by the cursor of evicted request by every storage backend
+func TestStoreRequestsPageEvicted(t *testing.T) {
+	for backend, newStore := range testBackends() {
+		newStore := newStore
+
+		t.Run(backend, func(t *testing.T) {
+			s := newStore(WithRetention(4, 0))
+			defer s.Close()
+
+			now := time.Now()
+
+			put := func(from, to int) {
+				for i := from; i < to; i++ {
+					req := &models.Request{
+						Name:    fmt.Sprintf("req%d", i),
+						Created: now.Add(time.Duration(i) * time.Second),
+					}
+					require.NoError(t, s.PutRequest("hook", req))
+				}
+			}
+
+			put(0, 4)
+
+			requests, next, err := s.RequestsPage("hook", "", 2, nil)
+			require.NoError(t, err)
+			require.Len(t, requests, 2)
+			assert.Equal(t, "req2", requests[1].Name)
+
+			// req2 is imported again with an older time and evicted along with req0,
+			// paging goes on from the position of req2
+			put(4, 5)
+			require.NoError(t, s.PutRequest("hook", &models.Request{Name: "req2", Created: now.Add(-time.Minute)}))
+			put(5, 6)
+
+			stored, err := s.Request("hook", "req2")
+			require.NoError(t, err)
+			require.Nil(t, stored)
+
+			requests, next, err = s.RequestsPage("hook", next, 2, nil)
+			require.NoError(t, err)
+			require.Len(t, requests, 1)
+			assert.Equal(t, "req1", requests[0].Name)
+			assert.Empty(t, next)
+		})
+	}
+}
+
+// TestStoreRetention checks eviction of the oldest requests by every storage backend
+func TestStoreRetention(t *testing.T) {
+	for backend, newStore := range testBackends() {
+		newStore := newStore
+
+		t.Run(backend, func(t *testing.T) {
+			s := newStore(WithRetention(5, 0))
+			defer s.Close()
+
+			now := time.Now()
+
+			for i := 0; i < 8; i++ {
+				req := &models.Request{
+					Name:    fmt.Sprintf("req%d", i),
+					Created: now.Add(time.Duration(i) * time.Second),
+				}
+				require.NoError(t, s.PutRequest("count", req))
+			}
+
+			requests, err := s.Requests("count")
+			require.NoError(t, err)
+			require.Len(t, requests, 5)
+			assert.Equal(t, "req7", requests[0].Name)
+			assert.Equal(t, "req3", requests[4].Name)
+
+			s = newStore(WithRetention(0, 3000))
+			defer s.Close()
+
+			for i := 0; i < 8; i++ {
+				req := &models.Request{
+					Name:    fmt.Sprintf("req%d", i),
+					Body:    []byte(strings.Repeat("x", 200)),
+					Created: now.Add(time.Duration(i) * time.Second),
+				}
+				require.NoError(t, s.PutRequest("size", req))
+			}
+
+			requests, err = s.Requests("size")
+			require.NoError(t, err)
+			assert.True(t, len(requests) > 1 && len(requests) < 8, len(requests))
+			assert.Equal(t, "req7", requests[0].Name)
+
+			big := &models.Request{
+				Name:    "big",
+				Body:    []byte(strings.Repeat("x", 4000)),
+				Created: now.Add(time.Minute),
+			}
+			require.NoError(t, s.PutRequest("size", big))
+
+			requests, err = s.Requests("size")
+			require.NoError(t, err)
+			require.Len(t, requests, 1) // the newest one is kept anyway
+			assert.Equal(t, "big", requests[0].Name)
+
+			counters, err := s.Counters()
+			require.NoError(t, err)
+			assert.Equal(t, 1, counters["size"])
+
+			// replaced requests are counted once, imported older ones are evicted first
+			s = newStore(WithRetention(3, 0))
+			defer s.Close()
+
+			for _, name := range []string{"req0", "req1", "req2", "req1"} {
+				req := &models.Request{Name: name, Created: now}
+				require.NoError(t, s.PutRequest("order", req))
+			}
+
+			require.NoError(t, s.PutRequest("order", &models.Request{Name: "early", Created: now.Add(-time.Hour)}))
+
+			requests, err = s.Requests("order")
+			require.NoError(t, err)
+			require.Len(t, requests, 3)
+			assert.Equal(t, []string{"req2", "req1", "req0"}, []string{requests[0].Name, requests[1].Name, requests[2].Name})
+
+			counters, err = s.Counters()
+			require.NoError(t, err)
+			assert.Equal(t, 3, counters["order"])
+		})
+	}
+}
+
+func testStoreHook(t *testing.T, s Store) {
+	exp := models.NewHook(true)
+	exp.Created = time.Now().UTC().Truncate(time.Millisecond)
+
+	require.NoError(t, s.PutHook(exp))
+
+	act, err := s.Hook(exp.Name)
+	require.NoError(t, err)
+	assert.Equal(t, exp, act)
+
+	act.Response = &models.Response{Status: http.StatusCreated}
+
+	act, err = s.Hook(exp.Name)
+	require.NoError(t, err)
+	assert.Nil(t, act.Response) // returned models are not shared with storage
+
+	act, err = s.Hook("unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, act)
+
+	exp.Forward = &models.Forward{URL: "http://localhost:3000"}
+	require.NoError(t, s.UpdateHook(exp))
+
+	act, err = s.Hook(exp.Name)
+	require.NoError(t, err)
+	assert.Equal(t, exp.Forward, act.Forward)
+	assert.Nil(t, act.Response)
+
+	assert.Error(t, s.UpdateHook(models.NewHook(false)))
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 1, counters["hooks"])
+}
+
+func testStoreRecentHooks(t *testing.T, s Store) {
+	now := time.Now()
+
+	for i := 0; i < 10; i++ {
+		public := models.NewHook(false)
+		public.Created = now.Add(time.Duration(-i) * time.Minute)
+		require.NoError(t, s.PutHook(public))
+
+		private := models.NewHook(true)
+		private.Created = now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, s.PutHook(private))
+	}
+
+	hooks, err := s.RecentHooks(5)
+	require.NoError(t, err)
+	assert.Len(t, hooks, 5)
+
+	for i, hook := range hooks {
+		assert.False(t, hook.Private)
+
+		if i > 0 {
+			assert.True(t, hook.Created.Before(hooks[i-1].Created), "newest first")
+		}
+	}
+
+	hooks, err = s.RecentHooks(50)
+	require.NoError(t, err)
+	assert.Len(t, hooks, 10)
+}
+
+func testStoreSweepHooks(t *testing.T, s Store) {
+	now := time.Now()
+
+	for i := 0; i < 10; i++ {
+		hook := models.NewHook(false)
+		hook.Name = fmt.Sprintf("hook%d", i)
+		hook.Created = now.Add(time.Duration(-i) * time.Hour)
+		require.NoError(t, s.PutHook(hook))
+
+		for j := 0; j < 3; j++ {
+			req := &models.Request{
+				Name:    fmt.Sprintf("req%d", j),
+				Created: now,
+			}
+			require.NoError(t, s.PutRequest(hook.Name, req))
+		}
+	}
+
+	require.NoError(t, s.SweepHooks(time.Duration(7)*time.Hour-time.Minute))
+
+	for i := 0; i < 10; i++ {
+		hook, err := s.Hook(fmt.Sprintf("hook%d", i))
+		require.NoError(t, err)
+
+		requests, err := s.Requests(fmt.Sprintf("hook%d", i))
+		require.NoError(t, err)
+
+		if i < 7 {
+			assert.NotNil(t, hook, i)
+			assert.Len(t, requests, 3, i)
+		} else {
+			assert.Nil(t, hook, i)
+			assert.Empty(t, requests, i)
+		}
+	}
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 7, counters["hooks"])
+	assert.Equal(t, 3, counters["hook0"])
+	assert.Equal(t, 0, counters["hook9"])
+}
+
+func testStoreSweepExpires(t *testing.T, s Store) {
+	now := time.Now()
+
+	expired := models.NewHook(false)
+	expired.Expires = now.Add(-time.Minute)
+	require.NoError(t, s.PutHook(expired))
+
+	longLived := models.NewHook(false)
+	longLived.Created = now.Add(-72 * time.Hour)
+	longLived.Expires = now.Add(24 * time.Hour)
+	require.NoError(t, s.PutHook(longLived))
+
+	legacy := models.NewHook(false)
+	legacy.Created = now.Add(-72 * time.Hour)
+	require.NoError(t, s.PutHook(legacy))
+
+	require.NoError(t, s.SweepHooks(48*time.Hour))
+
+	for name, exists := range map[string]bool{expired.Name: false, longLived.Name: true, legacy.Name: false} {
+		hook, err := s.Hook(name)
+		require.NoError(t, err)
+		assert.Equal(t, exists, hook != nil, name)
+	}
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 1, counters["hooks"])
+}
+
+func testStoreSweepDeleted(t *testing.T, s Store) {
+	names := make([]string, 0, 20)
+
+	for i := 0; i < 20; i++ {
+		hook := models.NewHook(false)
+		hook.Expires = time.Now().Add(-time.Minute)
+		require.NoError(t, s.PutHook(hook))
+
+		names = append(names, hook.Name)
+	}
+
+	// hooks deleted while sweeping are counted once
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for _, name := range names {
+			assert.NoError(t, s.DeleteHook(name))
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		assert.NoError(t, s.SweepHooks(48*time.Hour))
+	}()
+
+	wg.Wait()
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 0, counters["hooks"])
+}
+
+func testStoreRequests(t *testing.T, s Store) {
+	hook := models.NewHook(false)
+	require.NoError(t, s.PutHook(hook))
+
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		r, err := http.NewRequest(http.MethodPost, "/"+hook.Name, strings.NewReader(fmt.Sprintf(`{"i": %d}`, i)))
+		require.NoError(t, err)
+
+		req := models.NewRequest(r, 1024)
+		req.Name = fmt.Sprintf("req%d", i)
+		req.Created = now.Add(time.Duration(i) * time.Second)
+		require.NoError(t, s.PutRequest(hook.Name, req))
+	}
+
+	requests, err := s.Requests(hook.Name)
+	require.NoError(t, err)
+	require.Len(t, requests, 5)
+	assert.Equal(t, "req4", requests[0].Name, "newest first")
+	assert.Equal(t, "req0", requests[4].Name)
+	assert.Equal(t, []byte(`{"i": 4}`), requests[0].Body)
+
+	act, err := s.Request(hook.Name, "req2")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"i": 2}`), act.Body)
+
+	act, err = s.Request(hook.Name, "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, act)
+
+	act, err = s.Request("unknown", "req2")
+	assert.NoError(t, err)
+	assert.Nil(t, act)
+
+	requests, err = s.Requests("unknown")
+	assert.NoError(t, err)
+	assert.Empty(t, requests)
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 5, counters[hook.Name])
+}
+
+func testStoreUpdateRequest(t *testing.T, s Store) {
+	hook := models.NewHook(false)
+	require.NoError(t, s.PutHook(hook))
+
+	req := &models.Request{
+		Name:    "foo",
+		Created: time.Now(),
+	}
+
+	assert.Error(t, s.UpdateRequest(hook.Name, req)) // not exists yet
+	require.NoError(t, s.PutRequest(hook.Name, req))
+
+	req.AddReplay(&models.Reply{Status: http.StatusAccepted})
+	require.NoError(t, s.UpdateRequest(hook.Name, req))
+
+	act, err := s.Request(hook.Name, req.Name)
+	require.NoError(t, err)
+	require.Len(t, act.Replays, 1)
+	assert.Equal(t, http.StatusAccepted, act.Replays[0].Status)
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 1, counters[hook.Name])
+}
+
+func testStoreRequestsPage(t *testing.T, s Store) {
+	now := time.Now()
+
+	for i := 0; i < 7; i++ {
+		method := http.MethodGet
+		if i%2 == 1 {
+			method = http.MethodPost
+		}
+
+		req := &models.Request{
+			Name:    fmt.Sprintf("req%d", i),
+			Method:  method,
+			Created: now.Add(time.Duration(i) * time.Second),
+		}
+		require.NoError(t, s.PutRequest("hook", req))
+	}
+
+	names := func(requests []*models.Request) []string {
+		res := make([]string, 0, len(requests))
+		for _, req := range requests {
+			res = append(res, req.Name)
+		}
+
+		return res
+	}
+
+	requests, next, err := s.RequestsPage("hook", "", 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req6", "req5", "req4"}, names(requests))
+	assert.Equal(t, Cursor(requests[2]), next)
+
+	requests, next, err = s.RequestsPage("hook", next, 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req3", "req2", "req1"}, names(requests))
+	assert.Equal(t, Cursor(requests[2]), next)
+
+	requests, next, err = s.RequestsPage("hook", next, 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req0"}, names(requests))
+	assert.Empty(t, next)
+
+	posts := func(req *models.Request) bool {
+		return req.Method == http.MethodPost
+	}
+
+	requests, next, err = s.RequestsPage("hook", "", 2, posts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req5", "req3"}, names(requests))
+	assert.Equal(t, Cursor(requests[1]), next)
+
+	requests, next, err = s.RequestsPage("hook", next, 2, posts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req1"}, names(requests))
+	assert.Empty(t, next)
+
+	requests, next, err = s.RequestsPage("hook", "", 0, nil)
+	require.NoError(t, err)
+	assert.Len(t, requests, 7)
+	assert.Empty(t, next)
+
+	// the cursor keeps the position without the request stored at it
+	requests, next, err = s.RequestsPage("hook", Cursor(&models.Request{Name: "gone", Created: now.Add(2500 * time.Millisecond)}), 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req2", "req1", "req0"}, names(requests))
+	assert.Empty(t, next)
+
+	for _, cursor := range []string{"unknown", "zz!.req1", "abc."} {
+		_, _, err = s.RequestsPage("hook", cursor, 3, nil)
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+
+	requests, next, err = s.RequestsPage("unknown", "", 3, nil)
+	require.NoError(t, err)
+	assert.Empty(t, requests)
+	assert.Empty(t, next)
+}
+
+func testStoreNextRequest(t *testing.T, s Store) {
+	now := time.Now()
+
+	for i := 0; i < 4; i++ {
+		method := http.MethodGet
+		if i%2 == 1 {
+			method = http.MethodPost
+		}
+
+		req := &models.Request{
+			Name:    fmt.Sprintf("req%d", i),
+			Method:  method,
+			Created: now.Add(time.Duration(i) * time.Second),
+		}
+		require.NoError(t, s.PutRequest("hook", req))
+	}
+
+	// requests created at the same time are ordered by name
+	require.NoError(t, s.PutRequest("hook", &models.Request{Name: "req1a", Method: http.MethodGet, Created: now.Add(time.Second)}))
+
+	posts := func(req *models.Request) bool {
+		return req.Method == http.MethodPost
+	}
+
+	next, err := s.NextRequest("hook", "req0", nil)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "req1", next.Name)
+
+	next, err = s.NextRequest("hook", "req1", nil)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "req1a", next.Name)
+
+	next, err = s.NextRequest("hook", "req1", posts)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "req3", next.Name)
+
+	next, err = s.NextRequest("hook", "req3", nil)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	next, err = s.NextRequest("hook", "unknown", nil)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	next, err = s.NextRequest("unknown", "req0", nil)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func testStoreHookConflict(t *testing.T, s Store) {
+	const workers = 10
+
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		go func(i int) {
+			hook := models.NewHook(false)
+			hook.Name = "stripe-staging"
+			hook.Owner = fmt.Sprintf("user%d", i)
+
+			errs <- s.PutHook(hook)
+		}(i)
+	}
+
+	created := 0
+
+	for i := 0; i < workers; i++ {
+		err := <-errs
+		if err == nil {
+			created++
+			continue
+		}
+
+		assert.ErrorIs(t, err, ErrHookExists)
+	}
+
+	assert.Equal(t, 1, created, "the name is taken once")
+
+	hook, err := s.Hook("stripe-staging")
+	require.NoError(t, err)
+	require.NotNil(t, hook)
+
+	hooks, err := s.UserHooks(hook.Owner)
+	require.NoError(t, err)
+	assert.Len(t, hooks, 1)
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 1, counters["hooks"])
+}
+
+func testStoreOwnedHooks(t *testing.T, s Store) {
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		hook := models.NewHook(i == 0)
+		hook.Name = fmt.Sprintf("owned%d", i)
+		hook.Owner = "team"
+		hook.Created = now.Add(time.Duration(-i) * 72 * time.Hour)
+		require.NoError(t, s.PutHook(hook))
+	}
+
+	other := models.NewHook(false)
+	other.Owner = "other"
+	require.NoError(t, s.PutHook(other))
+
+	anonymous := models.NewHook(false)
+	anonymous.Created = now.Add(-72 * time.Hour)
+	require.NoError(t, s.PutHook(anonymous))
+
+	require.NoError(t, s.SweepHooks(48*time.Hour))
+
+	hooks, err := s.UserHooks("team")
+	require.NoError(t, err)
+	require.Len(t, hooks, 3) // owned hooks never expire
+
+	for i, hook := range hooks {
+		assert.Equal(t, fmt.Sprintf("owned%d", i), hook.Name, "newest first")
+	}
+
+	hook, err := s.Hook(anonymous.Name)
+	require.NoError(t, err)
+	assert.Nil(t, hook)
+
+	hooks, err = s.UserHooks("unknown")
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+
+	// hook handed over to another owner
+	other.Owner = "team"
+	require.NoError(t, s.UpdateHook(other))
+
+	hooks, err = s.UserHooks("team")
+	require.NoError(t, err)
+	assert.Len(t, hooks, 4)
+
+	hooks, err = s.UserHooks("other")
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+}
+
+func testStoreRenameHook(t *testing.T, s Store) {
+	hook := models.NewHook(false)
+	hook.Name = "old"
+	hook.Owner = "team"
+	require.NoError(t, s.PutHook(hook))
+	require.NoError(t, s.PutRequest("old", &models.Request{Name: "req", Path: "/old/orders", Created: time.Now()}))
+
+	taken := models.NewHook(false)
+	taken.Name = "taken"
+	require.NoError(t, s.PutHook(taken))
+
+	assert.ErrorIs(t, s.RenameHook("old", "taken"), ErrHookExists)
+	assert.Error(t, s.RenameHook("unknown", "new"))
+
+	require.NoError(t, s.RenameHook("old", "new"))
+
+	act, err := s.Hook("old")
+	require.NoError(t, err)
+	assert.Nil(t, act)
+
+	act, err = s.Hook("new")
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	assert.Equal(t, "new", act.Name)
+
+	requests, err := s.Requests("new")
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+
+	// replays and forwarding append the subpath following the new name
+	assert.Equal(t, "/new/orders", requests[0].Path)
+	assert.Equal(t, "/orders", requests[0].Subpath("new"))
+
+	req, err := s.Request("new", "req")
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, "/new/orders", req.Path)
+
+	requests, err = s.Requests("old")
+	require.NoError(t, err)
+	assert.Empty(t, requests)
+
+	hooks, err := s.UserHooks("team")
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, "new", hooks[0].Name)
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 2, counters["hooks"])
+	assert.Equal(t, 1, counters["new"])
+	assert.Equal(t, 0, counters["old"])
+}
+
+func testStoreDeleteHook(t *testing.T, s Store) {
+	hook := models.NewHook(false)
+	hook.Owner = "team"
+	require.NoError(t, s.PutHook(hook))
+	require.NoError(t, s.PutRequest(hook.Name, &models.Request{Name: "req", Created: time.Now()}))
+
+	require.NoError(t, s.DeleteHook(hook.Name))
+	require.NoError(t, s.DeleteHook("unknown"))
+
+	act, err := s.Hook(hook.Name)
+	require.NoError(t, err)
+	assert.Nil(t, act)
+
+	requests, err := s.Requests(hook.Name)
+	require.NoError(t, err)
+	assert.Empty(t, requests)
+
+	for _, list := range []func() ([]*models.Hook, error){
+		func() ([]*models.Hook, error) { return s.UserHooks("team") },
+		func() ([]*models.Hook, error) { return s.RecentHooks(10) },
+	} {
+		hooks, err := list()
+		require.NoError(t, err)
+		assert.Empty(t, hooks)
+	}
+
+	counters, err := s.Counters()
+	require.NoError(t, err)
+	assert.Equal(t, 0, counters["hooks"])
+}
+
+func testStoreUsers(t *testing.T, s Store) {
+	user, err := models.NewUser("team", "password")
+	require.NoError(t, err)
+	user.Created = time.Now().UTC().Truncate(time.Millisecond)
+
+	require.NoError(t, s.PutUser(user))
+	assert.ErrorIs(t, s.PutUser(user), ErrUserExists)
+
+	act, err := s.User("team")
+	require.NoError(t, err)
+	assert.Equal(t, user, act)
+	assert.True(t, act.CheckPassword("password"))
+
+	act, err = s.User("unknown")
+	require.NoError(t, err)
+	assert.Nil(t, act)
+}
+
+func testStoreSessions(t *testing.T, s Store) {
+	active, err := models.NewSession("team", time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, s.PutSession(active))
+
+	expired, err := models.NewSession("team", -time.Second)
+	require.NoError(t, err)
+	require.NoError(t, s.PutSession(expired))
+
+	act, err := s.Session(active.ID)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	assert.Equal(t, "team", act.User)
+
+	act, err = s.Session(expired.ID)
+	require.NoError(t, err)
+	assert.Nil(t, act)
+
+	require.NoError(t, s.SweepSessions())
+	require.NoError(t, s.DeleteSession(active.ID))
+
+	act, err = s.Session(active.ID)
+	require.NoError(t, err)
+	assert.Nil(t, act)
+}
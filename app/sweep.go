@@ -17,6 +17,10 @@ func (a *App) sweep(ctx context.Context) {
 			if err := a.Storage.SweepHooks(maxAge); err != nil {
 				log.Fatalf("[ERROR] failed to sweep hooks, %+v", err)
 			}
+
+			if err := a.Storage.SweepSessions(); err != nil {
+				log.Fatalf("[ERROR] failed to sweep sessions, %+v", err)
+			}
 		case <-ctx.Done():
 			return
 		}
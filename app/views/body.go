@@ -0,0 +1,371 @@
+package views
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+// Body viewers
+const (
+	ViewerJSON = "json"
+	ViewerXML  = "xml"
+	ViewerForm = "form"
+	ViewerHex  = "hex"
+	ViewerText = "text"
+)
+
+const (
+	maxDecodedSize = 1 << 20 // limit of decompressed payloads
+	maxHexDumpSize = 16 << 10
+	minBase64Size  = 16 // shorter bodies are rather plain words
+)
+
+var base64Re = regexp.MustCompile(`^[A-Za-z0-9+/\r\n]+={0,2}$`)
+
+// Body is a request body decoded for display by its content type
+type Body struct {
+	Viewer    string
+	Decoded   []string // decodings applied to the stored body in order, e.g. base64, gzip
+	Text      string   // formatted body of text viewers or hex dump of binary one
+	Tree      *JSONNode
+	Form      []FormField
+	Truncated bool // only a part of the decoded body is shown
+}
+
+// JSONNode is a node of collapsible JSON tree
+type JSONNode struct {
+	Key      string // object key or array index, empty for the root
+	Kind     string // object, array, string, number, bool or null
+	Value    string // scalar value
+	Children []*JSONNode
+}
+
+// FormField is a single value of url-encoded form
+type FormField struct {
+	Key   string
+	Value string
+}
+
+// NewBody decodes the stored request body choosing the viewer by the content type
+func NewBody(req *models.Request) *Body {
+	b := &Body{}
+	body := b.decode(req.Body, req.Headers.Get("Content-Encoding"))
+	contentType := req.ContentType
+
+	if len(b.Decoded) > 0 {
+		contentType = "" // the declared type is of the encoded payload
+	}
+
+	switch {
+	case isJSON(contentType, body) && b.viewJSON(body):
+	case isXML(contentType, body) && b.viewXML(body):
+	case contentType == "application/x-www-form-urlencoded" && b.viewForm(body):
+	case !utf8.Valid(body) || bytes.IndexByte(body, 0) != -1:
+		b.viewHex(body)
+	default:
+		b.Viewer = ViewerText
+		b.Text = string(body)
+	}
+
+	return b
+}
+
+// decode unwraps gzip and base64 payloads, the original body is returned if it is not encoded
+func (b *Body) decode(body []byte, encoding string) []byte {
+	if isGzip(body) || strings.EqualFold(encoding, "gzip") {
+		if decoded, ok := b.gunzip(body); ok {
+			b.Decoded = append(b.Decoded, "gzip")
+			return decoded
+		}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < minBase64Size || !base64Re.Match(trimmed) {
+		return body
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.NewReplacer("\r", "", "\n", "").Replace(string(trimmed)))
+	if err != nil {
+		return body
+	}
+
+	// accept only payloads recognized after decoding as random text is often valid base64
+	if isGzip(decoded) {
+		if unzipped, ok := b.gunzip(decoded); ok {
+			b.Decoded = append(b.Decoded, "base64", "gzip")
+			return unzipped
+		}
+	}
+
+	if json.Valid(decoded) || isXML("", decoded) {
+		b.Decoded = append(b.Decoded, "base64")
+		return decoded
+	}
+
+	return body
+}
+
+func (b *Body) gunzip(body []byte) ([]byte, bool) {
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, false
+	}
+
+	decoded, err := ioutil.ReadAll(io.LimitReader(r, maxDecodedSize+1))
+	if err != nil && len(decoded) == 0 {
+		return nil, false
+	}
+
+	if len(decoded) > maxDecodedSize {
+		decoded = decoded[:maxDecodedSize]
+		b.Truncated = true
+	}
+
+	return decoded, true
+}
+
+func (b *Body) viewJSON(body []byte) bool {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	tree, err := parseJSONNode(dec, "")
+	if err != nil {
+		return false
+	}
+
+	b.Viewer = ViewerJSON
+	b.Text = indented.String()
+	b.Tree = tree
+
+	return true
+}
+
+func (b *Body) viewXML(body []byte) bool {
+	text, err := indentXML(body)
+	if err != nil {
+		return false
+	}
+
+	b.Viewer = ViewerXML
+	b.Text = text
+
+	return true
+}
+
+func (b *Body) viewForm(body []byte) bool {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return false
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range values[key] {
+			b.Form = append(b.Form, FormField{Key: key, Value: value})
+		}
+	}
+
+	b.Viewer = ViewerForm
+	b.Text = string(body)
+
+	return true
+}
+
+func (b *Body) viewHex(body []byte) {
+	if len(body) > maxHexDumpSize {
+		body = body[:maxHexDumpSize]
+		b.Truncated = true
+	}
+
+	b.Viewer = ViewerHex
+	b.Text = hex.Dump(body)
+}
+
+// parseJSONNode reads the next value of the decoder keeping the order of object keys
+func parseJSONNode(dec *json.Decoder, key string) (*JSONNode, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &JSONNode{Key: key}
+
+	switch v := token.(type) {
+	case json.Delim:
+		node.Kind = "array"
+		if v == '{' {
+			node.Kind = "object"
+		}
+
+		for i := 0; dec.More(); i++ {
+			childKey := ""
+
+			if node.Kind == "object" {
+				t, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				childKey, _ = t.(string)
+			} else {
+				childKey = strconv.Itoa(i)
+			}
+
+			child, err := parseJSONNode(dec, childKey)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Children = append(node.Children, child)
+		}
+
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+	case string:
+		node.Kind = "string"
+		node.Value = v
+	case json.Number:
+		node.Kind = "number"
+		node.Value = v.String()
+	case bool:
+		node.Kind = "bool"
+		node.Value = "false"
+		if v {
+			node.Value = "true"
+		}
+	default:
+		node.Kind = "null"
+		node.Value = "null"
+	}
+
+	return node, nil
+}
+
+// indentXML formats the document keeping namespace prefixes as written
+func indentXML(body []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+
+	var buf bytes.Buffer
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+
+	for n := 0; ; n++ {
+		token, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			t.Name = rawName(t.Name)
+			for i := range t.Attr {
+				t.Attr[i].Name = rawName(t.Attr[i].Name)
+			}
+
+			token = t
+		case xml.EndElement:
+			t.Name = rawName(t.Name)
+			token = t
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		case xml.ProcInst:
+			if n > 0 {
+				continue // only the leading declaration is allowed by the encoder
+			}
+		}
+
+		if err := enc.EncodeToken(token); err != nil {
+			return "", err
+		}
+
+		if _, ok := token.(xml.ProcInst); ok {
+			if err := enc.Flush(); err != nil {
+				return "", err
+			}
+
+			buf.WriteByte('\n') // the encoder puts the root element on the same line
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+
+	if buf.Len() == 0 {
+		return "", io.ErrUnexpectedEOF
+	}
+
+	return buf.String(), nil
+}
+
+func rawName(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
+
+func isJSON(contentType string, body []byte) bool {
+	if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(body)
+
+	return sniffable(contentType) && len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+func isXML(contentType string, body []byte) bool {
+	switch {
+	case contentType == "application/xml", contentType == "text/xml", strings.HasSuffix(contentType, "+xml"):
+		return true
+	case !sniffable(contentType):
+		return false
+	}
+
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("<"))
+}
+
+// sniffable reports whether the payload type could be guessed by the body
+func sniffable(contentType string) bool {
+	return contentType == "" || contentType == "text/plain" || contentType == "application/octet-stream"
+}
+
+func isGzip(body []byte) bool {
+	return len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b
+}
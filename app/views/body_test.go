@@ -0,0 +1,127 @@
+package views
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotzero/hooks/app/models"
+)
+
+func TestNewBodyJSON(t *testing.T) {
+	b := NewBody(&models.Request{
+		ContentType: "application/json",
+		Body:        []byte(`{"zeta":1,"alpha":[true,null,"x"],"obj":{}}`),
+	})
+
+	assert.Equal(t, ViewerJSON, b.Viewer)
+	assert.Equal(t, "{\n  \"zeta\": 1,\n  \"alpha\": [\n    true,\n    null,\n    \"x\"\n  ],\n  \"obj\": {}\n}", b.Text)
+
+	require.NotNil(t, b.Tree)
+	assert.Equal(t, "object", b.Tree.Kind)
+	require.Len(t, b.Tree.Children, 3)
+
+	// the order of keys is kept
+	assert.Equal(t, &JSONNode{Key: "zeta", Kind: "number", Value: "1"}, b.Tree.Children[0])
+	assert.Equal(t, "alpha", b.Tree.Children[1].Key)
+	assert.Equal(t, "array", b.Tree.Children[1].Kind)
+	assert.Equal(t, &JSONNode{Key: "1", Kind: "null", Value: "null"}, b.Tree.Children[1].Children[1])
+	assert.Equal(t, &JSONNode{Key: "obj", Kind: "object"}, b.Tree.Children[2])
+
+	// truncated json falls back to text
+	b = NewBody(&models.Request{ContentType: "application/json", Body: []byte(`{"zeta":1,"al`)})
+	assert.Equal(t, ViewerText, b.Viewer)
+	assert.Equal(t, `{"zeta":1,"al`, b.Text)
+
+	// the type of plain text is guessed by the body
+	b = NewBody(&models.Request{ContentType: "text/plain", Body: []byte(`[1, 2]`)})
+	assert.Equal(t, ViewerJSON, b.Viewer)
+}
+
+func TestNewBodyXML(t *testing.T) {
+	soap := `<?xml version="1.0"?><soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">` +
+		`<soap:Body><m:Price xmlns:m="https://example.com/price" m:currency="EUR">10</m:Price></soap:Body></soap:Envelope>`
+
+	b := NewBody(&models.Request{ContentType: "application/soap+xml", Body: []byte(soap)})
+
+	assert.Equal(t, ViewerXML, b.Viewer)
+	assert.Equal(t, `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">
+  <soap:Body>
+    <m:Price xmlns:m="https://example.com/price" m:currency="EUR">10</m:Price>
+  </soap:Body>
+</soap:Envelope>`, b.Text)
+
+	b = NewBody(&models.Request{ContentType: "text/xml", Body: []byte(`<a><b></a>`)})
+	assert.Equal(t, ViewerText, b.Viewer)
+}
+
+func TestNewBodyForm(t *testing.T) {
+	b := NewBody(&models.Request{
+		ContentType: "application/x-www-form-urlencoded",
+		Body:        []byte("b=2&a=1&b=3&c=hello+world"),
+	})
+
+	assert.Equal(t, ViewerForm, b.Viewer)
+	assert.Equal(t, []FormField{{"a", "1"}, {"b", "2"}, {"b", "3"}, {"c", "hello world"}}, b.Form)
+	assert.Equal(t, "b=2&a=1&b=3&c=hello+world", b.Text)
+}
+
+func TestNewBodyDecoded(t *testing.T) {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`{"event":"compressed"}`))
+	require.NoError(t, zw.Close())
+
+	b := NewBody(&models.Request{
+		ContentType: "application/json",
+		Headers:     http.Header{"Content-Encoding": {"gzip"}},
+		Body:        buf.Bytes(),
+	})
+
+	assert.Equal(t, ViewerJSON, b.Viewer)
+	assert.Equal(t, []string{"gzip"}, b.Decoded)
+	assert.Contains(t, b.Text, `"event": "compressed"`)
+
+	b = NewBody(&models.Request{
+		ContentType: "text/plain",
+		Body:        []byte(base64.StdEncoding.EncodeToString(buf.Bytes())),
+	})
+
+	assert.Equal(t, ViewerJSON, b.Viewer)
+	assert.Equal(t, []string{"base64", "gzip"}, b.Decoded)
+
+	b = NewBody(&models.Request{
+		Body: []byte(base64.StdEncoding.EncodeToString([]byte(`<note>hello</note>`))),
+	})
+
+	assert.Equal(t, ViewerXML, b.Viewer)
+	assert.Equal(t, []string{"base64"}, b.Decoded)
+
+	// words which happen to be valid base64 are kept as is
+	b = NewBody(&models.Request{ContentType: "text/plain", Body: []byte("HelloWorldHelloWorld")})
+
+	assert.Equal(t, ViewerText, b.Viewer)
+	assert.Empty(t, b.Decoded)
+	assert.Equal(t, "HelloWorldHelloWorld", b.Text)
+}
+
+func TestNewBodyHex(t *testing.T) {
+	b := NewBody(&models.Request{ContentType: "application/octet-stream", Body: []byte{0x00, 0x01, 'h', 'i', 0xff}})
+
+	assert.Equal(t, ViewerHex, b.Viewer)
+	assert.Equal(t, "00000000  00 01 68 69 ff                                    |..hi.|\n", b.Text)
+	assert.False(t, b.Truncated)
+
+	b = NewBody(&models.Request{Body: []byte(strings.Repeat("\x00", maxHexDumpSize+1))})
+
+	assert.Equal(t, ViewerHex, b.Viewer)
+	assert.True(t, b.Truncated)
+}
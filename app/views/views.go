@@ -1,23 +1,73 @@
 package views
 
 import (
+	"net/url"
+	"time"
+
 	"github.com/dotzero/hooks/app/models"
+	"github.com/dotzero/hooks/app/snippet"
 )
 
 // Common view struct
 type Common struct {
 	BaseURL string
-	TTL     int
+	TTL     int // default ttl of hooks in hours
+	MaxTTL  int // max ttl of hooks in hours
 	Recent  []*models.Hook
+	User    string // signed in user, empty for anonymous
 }
 
 // Home view struct
 type Home struct {
-	Common Common
+	Common   Common
+	Hooks    []*models.Hook // hooks of the signed in user
+	Counters map[string]int // number of requests by hook names
 }
 
 // Hook view struct
 type Hook struct {
-	Common Common
-	Hook   *models.Hook
+	Common       Common
+	Hook         *models.Hook
+	Owned        bool      // hook is owned by the signed in user
+	Configurable bool      // settings could be changed by the owner or with the hook secret
+	Expires      time.Time // zero for owned hooks
+	Requests     []*Request
+	Before       string     // cursor of the current page, empty for the newest requests
+	Next         string     // cursor of the next page of older requests
+	Filters      url.Values // search filters of the listing
+}
+
+// Request view struct
+type Request struct {
+	BaseURL      string
+	Hook         string
+	Request      *models.Request
+	Configurable bool // the request could be replayed by the owner or with the hook secret
+}
+
+// Body returns the request body decoded for display
+func (r *Request) Body() *Body {
+	return NewBody(r.Request)
+}
+
+// Snippets returns languages of code snippets reproducing the request,
+// the code is loaded on demand as every snippet embeds the whole body
+func (r *Request) Snippets() []*snippet.Snippet {
+	return snippet.Languages()
+}
+
+// NewRequests wraps request models into views
+func NewRequests(baseURL string, hook string, configurable bool, requests []*models.Request) []*Request {
+	views := make([]*Request, 0, len(requests))
+
+	for _, req := range requests {
+		views = append(views, &Request{
+			BaseURL:      baseURL,
+			Hook:         hook,
+			Request:      req,
+			Configurable: configurable,
+		})
+	}
+
+	return views
 }
@@ -10,6 +10,7 @@ import (
 	flags "github.com/jessevdk/go-flags"
 
 	"github.com/dotzero/hooks/app"
+	"github.com/dotzero/hooks/app/notify"
 )
 
 var (
@@ -24,16 +25,27 @@ var (
 
 // Opts with command line flags and env
 type Opts struct {
-	Host       string `long:"host" env:"HOOKS_HOST" default:"0.0.0.0" description:"listening address"`
-	Port       int    `long:"port" env:"HOOKS_PORT" default:"8080" description:"listening port"`
-	AppURL     string `long:"url" env:"HOOKS_URL" default:"http://0.0.0.0:8080" description:"url to app"`
-	BoltPath   string `long:"bolt-path" env:"BOLT_PATH" default:"./var" description:"parent directory for the bolt files"`
-	BoltTTL    int    `long:"bolt-ttl" env:"BOLT_TTL_HOURS" default:"48" description:"TTL in hours to keep data"`
-	StaticPath string `long:"static-path" env:"STATIC_PATH" default:"./static" description:"path to website assets"`
-	TmlPath    string `long:"tpl-path" env:"TPL_PATH" default:"./templates" description:"path to templates files"`
-	TplExt     string `long:"tpl-ext" env:"TPL_EXT" default:".html" description:"templates files extensions"`
-	Verbose    bool   `long:"verbose" description:"verbose logging"`
-	Version    bool   `short:"v" long:"version" description:"show the version number"`
+	Host         string `long:"host" env:"HOOKS_HOST" default:"0.0.0.0" description:"listening address"`
+	Port         int    `long:"port" env:"HOOKS_PORT" default:"8080" description:"listening port"`
+	AppURL       string `long:"url" env:"HOOKS_URL" default:"http://0.0.0.0:8080" description:"url to app"`
+	StoreType    string `long:"store" env:"STORE_TYPE" default:"bolt" choice:"bolt" choice:"memory" choice:"sqlite" description:"storage backend"`
+	BoltPath     string `long:"bolt-path" env:"BOLT_PATH" default:"./var" description:"parent directory for the bolt and sqlite files"`
+	BoltTTL      int    `long:"bolt-ttl" env:"BOLT_TTL_HOURS" default:"48" description:"TTL in hours to keep data"`
+	MaxTTL       int    `long:"max-ttl" env:"MAX_TTL_HOURS" default:"168" description:"max TTL in hours that could be requested for a hook"`
+	MaxBodySize  int64  `long:"max-body-size" env:"MAX_BODY_SIZE" default:"1048576" description:"max size in bytes of stored request body"`
+	MaxRequests  int    `long:"max-requests" env:"MAX_REQUESTS" default:"1000" description:"max number of stored requests per hook, 0 is unlimited"`
+	MaxHookSize  int64  `long:"max-hook-size" env:"MAX_HOOK_SIZE" default:"104857600" description:"max total size in bytes of stored requests per hook, 0 is unlimited"`
+	AllowPrivate bool   `long:"allow-private" env:"ALLOW_PRIVATE" description:"allow forwarding, replays and webhook notifications to loopback and private network addresses"`
+	StaticPath   string `long:"static-path" env:"STATIC_PATH" default:"./static" description:"path to website assets"`
+	TmlPath      string `long:"tpl-path" env:"TPL_PATH" default:"./templates" description:"path to templates files"`
+	TplExt       string `long:"tpl-ext" env:"TPL_EXT" default:".html" description:"templates files extensions"`
+	SMTPHost     string `long:"smtp-host" env:"SMTP_HOST" description:"SMTP server host to send email notifications"`
+	SMTPPort     int    `long:"smtp-port" env:"SMTP_PORT" default:"587" description:"SMTP server port, STARTTLS is used when supported"`
+	SMTPUsername string `long:"smtp-username" env:"SMTP_USERNAME" description:"SMTP username"`
+	SMTPPassword string `long:"smtp-password" env:"SMTP_PASSWORD" description:"SMTP password"`
+	SMTPFrom     string `long:"smtp-from" env:"SMTP_FROM" description:"sender address of email notifications"`
+	Verbose      bool   `long:"verbose" description:"verbose logging"`
+	Version      bool   `short:"v" long:"version" description:"show the version number"`
 }
 
 func main() {
@@ -50,15 +62,34 @@ func main() {
 	}
 
 	setupLog(opts.Verbose)
-	log.Printf("[DEBUG] opts: %+v", opts)
+	logOpts := opts
+	if logOpts.SMTPPassword != "" {
+		logOpts.SMTPPassword = "***"
+	}
+
+	log.Printf("[DEBUG] opts: %+v", logOpts)
 
 	app, err := app.New(app.CommonOpts{
-		AppURL:     opts.AppURL,
-		BoltPath:   opts.BoltPath,
-		BoltTTL:    opts.BoltTTL,
-		StaticPath: opts.StaticPath,
-		TmlPath:    opts.TmlPath,
-		TplExt:     opts.TplExt,
+		AppURL:       opts.AppURL,
+		StoreType:    opts.StoreType,
+		BoltPath:     opts.BoltPath,
+		BoltTTL:      opts.BoltTTL,
+		MaxTTL:       opts.MaxTTL,
+		MaxBodySize:  opts.MaxBodySize,
+		MaxRequests:  opts.MaxRequests,
+		MaxHookSize:  opts.MaxHookSize,
+		StaticPath:   opts.StaticPath,
+		TmlPath:      opts.TmlPath,
+		TplExt:       opts.TplExt,
+		Version:      Version,
+		AllowPrivate: opts.AllowPrivate,
+		SMTP: notify.SMTP{
+			Host:     opts.SMTPHost,
+			Port:     opts.SMTPPort,
+			Username: opts.SMTPUsername,
+			Password: opts.SMTPPassword,
+			From:     opts.SMTPFrom,
+		},
 	})
 	if err != nil {
 		log.Fatalf("[ERROR] failed to setup application, %+v", err)